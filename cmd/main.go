@@ -1,7 +1,80 @@
 package main
 
-import "petstore/internal"
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"petstore/internal"
+	"petstore/internal/config"
+	"strconv"
+)
+
+const usageMigrate = "usage: main migrate <up|down [N]|status> [--dry-run] [config flags]"
 
 func main() {
-	internal.RunApp()
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "migrate" {
+		migrate(args[1:])
+		return
+	}
+
+	cfg := loadConfig(args)
+	if err := internal.RunApp(cfg); err != nil {
+		log.Fatalln("server failed:", err)
+	}
+}
+
+// migrate run command of migrations, down reverts one migration if N is not set.
+func migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatalln(usageMigrate)
+	}
+
+	command := args[0]
+	args = args[1:]
+	if command != internal.MigrateUp && command != internal.MigrateDown && command != internal.MigrateStatus {
+		log.Fatalln(usageMigrate)
+	}
+
+	steps := 1
+	if command == internal.MigrateDown && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 {
+				log.Fatalln("number of migrations must be positive:", n)
+			}
+
+			steps = n
+			args = args[1:]
+		}
+	}
+
+	dryRun := false
+	configArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--dry-run" || arg == "-dry-run" {
+			dryRun = true
+			continue
+		}
+
+		configArgs = append(configArgs, arg)
+	}
+
+	cfg := loadConfig(configArgs)
+	if err := internal.RunMigrate(cfg, command, steps, dryRun); err != nil {
+		log.Fatalln("migrate failed:", err)
+	}
+}
+
+func loadConfig(args []string) *config.Config {
+	cfg, err := config.Load(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+
+		log.Fatalln("invalid config:", err)
+	}
+
+	return cfg
 }
@@ -1,26 +1,50 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/jwtauth/v5"
-	"github.com/joho/godotenv"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/ptflp/godecoder"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	_blobController "petstore/internal/blob/controller"
+	_blobRepo "petstore/internal/blob/repository"
+	"petstore/internal/config"
+	"petstore/internal/domain"
+	"petstore/internal/health"
+	_healthController "petstore/internal/health/controller"
+	_healthRepo "petstore/internal/health/repository"
+	_healthUsecase "petstore/internal/health/usecase"
+	"petstore/internal/logging"
+	"petstore/internal/memory"
+	"petstore/internal/metrics"
+	"petstore/internal/migration"
 	"petstore/internal/responder"
+	"petstore/internal/tracing"
+	"petstore/internal/transaction"
 	_userController "petstore/internal/user/controller"
 	_userMiddleware "petstore/internal/user/controller/middleware"
 	_userRepo "petstore/internal/user/repository"
 	_userUsecase "petstore/internal/user/usecase"
 
 	_petController "petstore/internal/pet/controller"
+	_petImaging "petstore/internal/pet/imaging"
 	_petRepo "petstore/internal/pet/repository"
 	_petUsecase "petstore/internal/pet/usecase"
 
@@ -28,6 +52,8 @@ import (
 	_orderRepo "petstore/internal/order/repository"
 	_orderUsecase "petstore/internal/order/usecase"
 
+	"strings"
+	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -44,80 +70,361 @@ import (
 //
 //	@host		localhost:8080
 //	@BasePath	/
-func RunApp() {
-	err := godotenv.Load()
+//
+// RunApp returns error if server fails, it is nil after graceful shutdown.
+func RunApp(cfg *config.Config) error {
+	logger := initLogger(cfg.Log)
+	defer logger.Sync()
 
-	if err != nil {
-		panic(err)
-	}
+	// logs without request context, e.g. of background work, use global logger
+	zap.ReplaceGlobals(logger)
+
+	logger.Info("config loaded", zap.Any("config", cfg))
 
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	shutdownTracing := initTracing(cfg.Tracing, logger)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	appMetrics := metrics.NewMetrics()
+
+	r.Use(logging.RequestID)
+	r.Use(tracing.Middleware)
+	r.Use(appMetrics.Middleware)
+	r.Use(logging.Middleware(logger, "/livez", "/healthz", "/readyz", "/metrics"))
+	r.Use(responder.Negotiate)
+
+	readiness := health.NewReadiness()
+
+	store := initStorage(cfg, appMetrics, logger)
+	defer store.close()
+
+	blobStore := initBlobStore(cfg.Blob, logger)
+	resp := responder.NewResponder(godecoder.NewDecoder(), logger)
+
+	tokenAuth := initTokenAuth(cfg.JWT, logger)
 
-	db := initDB()
-	resp := responder.NewResponder(godecoder.NewDecoder(), zap.NewExample())
-	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil, jwt.WithAcceptableSkew(30*time.Second))
+	userUsecase := appMetrics.WrapUserUsecase(
+		_userUsecase.NewUserUsecase(store.users, store.auth, tokenAuth, store.txManager))
+	if cfg.Storage == config.StorageMemory && cfg.Memory.AdminPassword != "" {
+		createAdmin(userUsecase, cfg.Memory.AdminPassword.Value(), logger)
+	}
+
+	authenticator := chi.Chain(jwtauth.Verifier(tokenAuth), _userMiddleware.Authenticator(resp, userUsecase)).Handler
+	optionalAuthenticator := chi.Chain(jwtauth.Verifier(tokenAuth),
+		_userMiddleware.OptionalAuthenticator(resp, userUsecase)).Handler
+
+	r.Group(func(r chi.Router) {
+		components := append(store.health, _healthUsecase.Component{
+			Name: "blob", Checker: domain.HealthCheckerFunc(func(ctx context.Context) (map[string]interface{}, error) {
+				return map[string]interface{}{"storage": cfg.Blob.Storage}, blobStore.Ping(ctx)
+			}),
+		})
+		healthUsecase := _healthUsecase.NewHealthUsecase(components, cfg.Health.Timeout)
 
-	userRepo := _userRepo.NewUserRepository(db)
-	auRepo := _userRepo.NewAuthRepository(db)
-	userUsecase := _userUsecase.NewUserUsecase(userRepo, auRepo, tokenAuth)
+		_healthController.NewHealthController(r, resp, healthUsecase, readiness, optionalAuthenticator)
+	})
+
+	r.Group(func(r chi.Router) {
+		_blobController.NewBlobController(r, resp, blobStore)
+	})
+
+	r.Method(http.MethodGet, "/metrics", appMetrics.Handler())
 
 	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
+		httpSwagger.URL(strings.TrimSuffix(cfg.Server.PublicURL, "/")+"/swagger/doc.json"),
 	))
 
 	r.Group(func(r chi.Router) {
-		_userController.NewUserController(r, resp, userUsecase)
+		_userController.NewUserController(r, resp, userUsecase, authenticator, optionalAuthenticator)
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(tokenAuth))
-		r.Use(_userMiddleware.Authenticator(resp, userUsecase))
+		r.Use(authenticator)
 
-		petRepo := _petRepo.NewPetRepository(db)
-		categoryRepo := _petRepo.NewCategoryRepository(db)
-		tagRepo := _petRepo.NewTagRepository(db)
+		imageProcessor := _petImaging.NewImageProcessor(cfg.Photo.Thumbnails)
 
-		petUsecase := _petUsecase.NewPetUsecase(petRepo, categoryRepo, tagRepo)
+		petUsecase := _petUsecase.NewPetUsecase(store.pets, store.categories, store.tags, store.photos, store.orders,
+			blobStore, imageProcessor, store.txManager)
+		petUsecase = appMetrics.WrapPetUsecase(petUsecase)
 		_petController.NewPetController(r, resp, petUsecase)
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(tokenAuth))
-		r.Use(_userMiddleware.Authenticator(resp, userUsecase))
+		r.Use(authenticator)
 
-		orderRepo := _orderRepo.NewOrderRepository(db)
-		orderUsecase := _orderUsecase.NewOrderUsecase(orderRepo)
+		orderUsecase := appMetrics.WrapOrderUsecase(
+			_orderUsecase.NewOrderUsecase(store.orders, store.pets, store.txManager))
 
 		_orderController.NewOrderController(r, resp, orderUsecase)
 	})
 
-	log.Println("server starting...")
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatal("failed run server", err)
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	return serve(server, cfg.Server, readiness, logger)
+}
+
+// serve run server until SIGINT or SIGTERM, then gracefully shutdown it:
+// fail readiness, wait drain delay, stop accepting connections and wait for in-flight requests.
+// Returns error if server can not run, e.g. address is in use.
+func serve(server *http.Server, cfg config.Server, readiness *health.Readiness, logger *zap.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting...", zap.String("addr", cfg.Addr))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		logger.Error("failed run server", zap.Error(err))
+		return err
+	case <-ctx.Done():
+	}
+
+	// second signal kills application immediately
+	stop()
+
+	logger.Info("server shutting down...", zap.Duration("drainDelay", cfg.DrainDelay))
+	readiness.Drain()
+	time.Sleep(cfg.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed graceful shutdown, closing connections", zap.Error(err))
+		_ = server.Close()
+	}
+
+	// ListenAndServe returns ErrServerClosed after shutdown, it is not failure
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+
+	logger.Info("server stopped")
+	return nil
 }
 
-func initDB() *sql.DB {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
+func initLogger(cfg config.Log) *zap.Logger {
+	level, _ := zapcore.ParseLevel(cfg.Level)
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Format == config.LogFormatConsole {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
 
-	pgInfo := fmt.Sprintf("host = %s port = %s "+
-		"user = %s password = %s dbname = %s sslmode = disable", host, port, user, password, dbname)
+	logger, err := zapConfig.Build()
+	if err != nil {
+		log.Panicln("failed to create logger", err)
+	}
 
-	db, err := sql.Open("postgres", pgInfo)
+	return logger
+}
 
+// initTracing set global tracer provider and W3C trace context propagator.
+// Returns function which flushes spans, it must be called on shutdown.
+func initTracing(cfg config.Tracing, logger *zap.Logger) func(ctx context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.TracingExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	default:
+		return func(context.Context) error { return nil }
+	}
 	if err != nil {
-		log.Panicln("failed to connect to database", err)
+		logger.Panic("failed to create trace exporter", zap.Error(err))
 	}
 
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		logger.Panic("failed to create trace resource", zap.Error(err))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown
+}
+
+// storage is repositories of data, they use the same database.
+type storage struct {
+	users      domain.UserRepository
+	auth       domain.AuthRepository
+	pets       domain.PetRepository
+	categories domain.CategoryRepository
+	tags       domain.TagRepository
+	photos     domain.PhotoRepository
+	orders     domain.OrderRepository
+	txManager  domain.TxManager
+	// health are critical components of storage for health checks
+	health []_healthUsecase.Component
+	close  func()
+}
+
+// initStorage create repositories of cfg.Storage.
+// Postgres is migrated if cfg.DB.AutoMigrate is true, memory storage starts empty.
+func initStorage(cfg *config.Config, appMetrics *metrics.Metrics, logger *zap.Logger) *storage {
+	if cfg.Storage == config.StorageMemory {
+		logger.Warn("data is stored in memory and is lost on restart")
+
+		db := memory.NewDB()
+		return &storage{
+			users:      _userRepo.NewMemoryUserRepository(db),
+			auth:       _userRepo.NewMemoryAuthRepository(db),
+			pets:       _petRepo.NewMemoryPetRepository(db),
+			categories: _petRepo.NewMemoryCategoryRepository(db),
+			tags:       _petRepo.NewMemoryTagRepository(db),
+			photos:     _petRepo.NewMemoryPhotoRepository(db),
+			orders:     _orderRepo.NewMemoryOrderRepository(db),
+			txManager:  memory.NewTxManager(db),
+			close:      func() {},
+		}
+	}
+
+	db := initDB(cfg.DB, logger)
+	appMetrics.RegisterDB(db, cfg.DB.Name)
+
+	migrator := initMigrator(db, logger)
+	if cfg.DB.AutoMigrate {
+		applyMigrations(migrator, logger)
+	}
+
+	return &storage{
+		users:      _userRepo.NewUserRepository(db),
+		auth:       _userRepo.NewAuthRepository(db),
+		pets:       _petRepo.NewPetRepository(db),
+		categories: _petRepo.NewCategoryRepository(db),
+		tags:       _petRepo.NewTagRepository(db),
+		photos:     _petRepo.NewPhotoRepository(db),
+		orders:     _orderRepo.NewOrderRepository(db),
+		txManager:  transaction.NewTxManager(db),
+		health: []_healthUsecase.Component{
+			{Name: "database", Checker: _healthRepo.NewDatabaseChecker(db), Critical: true},
+			{Name: "migrations", Checker: _healthRepo.NewMigrationChecker(migrator), Critical: true},
+		},
+		close: func() {
+			if err := db.Close(); err != nil {
+				logger.Error("failed to close database", zap.Error(err))
+			}
+		},
+	}
+}
+
+// createAdmin create user admin, memory storage has no other way to get first admin.
+func createAdmin(userUsecase domain.UserUsecase, password string, logger *zap.Logger) {
+	admin := &domain.UserInput{Username: "admin", Password: password, Role: domain.RoleAdmin}
+	if err := userUsecase.CreateList(context.Background(), []*domain.UserInput{admin}); err != nil {
+		logger.Panic("failed to create admin", zap.Error(err))
+	}
+}
+
+func initDB(cfg config.DB, logger *zap.Logger) *sql.DB {
+	db, err := sql.Open("postgres", cfg.ConnString())
+	if err != nil {
+		logger.Panic("failed to connect to database", zap.Error(err))
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
 	if err := db.Ping(); err != nil {
-		log.Panicln("failed to ping database", err)
+		logger.Panic("failed to ping database", zap.Error(err))
 	}
 
 	return db
 }
+
+// applyMigrations apply pending migrations, replicas wait for each other on lock of migrations.
+func applyMigrations(migrator *migration.Migrator, logger *zap.Logger) {
+	if err := migrator.Up(context.Background(), false, nil); err != nil {
+		logger.Panic("failed to apply migrations", zap.Error(err))
+	}
+
+	logger.Info("database is migrated", zap.Int64("version", migrator.Latest()))
+}
+
+// initBlobStore create storage for uploaded files.
+func initBlobStore(cfg config.Blob, logger *zap.Logger) domain.BlobStore {
+	switch cfg.Storage {
+	case config.BlobStorageS3:
+		blobStore, err := _blobRepo.NewS3BlobStore(context.Background(), _blobRepo.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey.Value(),
+			UseSSL:    cfg.S3.UseSSL,
+		})
+		if err != nil {
+			logger.Panic("failed to connect to s3 storage", zap.Error(err))
+		}
+
+		return blobStore
+	default:
+		return _blobRepo.NewLocalBlobStore(cfg.LocalDir)
+	}
+}
+
+// initTokenAuth create signer and verifier of tokens.
+// HMAC algorithms use secret, asymmetric algorithms use private key and public key derived from it.
+func initTokenAuth(cfg config.JWT, logger *zap.Logger) *jwtauth.JWTAuth {
+	skew := jwt.WithAcceptableSkew(cfg.AcceptableSkew)
+	if cfg.IsHMAC() {
+		return jwtauth.New(cfg.Algorithm, []byte(cfg.Secret.Value()), nil, skew)
+	}
+
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		logger.Panic("failed to read jwt private key", zap.Error(err))
+	}
+
+	privateKey, err := jwk.ParseKey(data, jwk.WithPEM(true))
+	if err != nil {
+		logger.Panic("failed to parse jwt private key", zap.Error(err))
+	}
+
+	publicKey, err := jwk.PublicKeyOf(privateKey)
+	if err != nil {
+		logger.Panic("failed to get jwt public key", zap.Error(err))
+	}
+
+	return jwtauth.New(cfg.Algorithm, privateKey, publicKey, skew)
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"os"
+	"petstore/internal/config"
+	"petstore/internal/health"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeReturnsListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	cfg := config.Server{Addr: listener.Addr().String(), ShutdownTimeout: time.Second}
+	server := &http.Server{Addr: cfg.Addr, Handler: http.NotFoundHandler()}
+
+	err = serve(server, cfg, health.NewReadiness(), zap.NewNop())
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("serve on busy address: got %v, want listen error", err)
+	}
+}
+
+func TestServeStopsOnSignal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cfg := config.Server{Addr: addr, ShutdownTimeout: time.Second}
+	server := &http.Server{Addr: addr, Handler: http.NotFoundHandler()}
+	readiness := health.NewReadiness()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- serve(server, cfg, readiness, zap.NewNop())
+	}()
+
+	// signal is sent only after server is listening, so it is handled by serve
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("server is not started: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("graceful shutdown: got %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+}
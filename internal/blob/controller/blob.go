@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/go-chi/chi"
+	"io"
+	"net/http"
+	"petstore/internal/domain"
+	"petstore/internal/responder"
+	"strconv"
+)
+
+type blobController struct {
+	responder responder.Responder
+	blobStore domain.BlobStore
+}
+
+func NewBlobController(r chi.Router, responder responder.Responder, bs domain.BlobStore) {
+	controller := &blobController{
+		responder: responder,
+		blobStore: bs,
+	}
+
+	r.Get("/static/*", controller.Get)
+}
+
+// Get this function is used to get a public file, for example photo of pet.
+func (b *blobController) Get(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+
+	blob, err := b.blobStore.Get(r.Context(), key)
+	if err != nil {
+		b.responder.Error(w, err)
+
+		return
+	}
+	defer blob.Body.Close()
+
+	if blob.ContentType != "" {
+		w.Header().Set("Content-Type", blob.ContentType)
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(blob.Size, 10))
+
+	_, _ = io.Copy(w, blob.Body)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"petstore/internal/domain"
+)
+
+// localBlobStore stores blobs as files in directory.
+type localBlobStore struct {
+	Dir string
+}
+
+func NewLocalBlobStore(dir string) domain.BlobStore {
+	return &localBlobStore{Dir: dir}
+}
+
+// filePath - convert key to file path inside directory.
+// Key can not leave directory.
+func (l *localBlobStore) filePath(key string) string {
+	return filepath.Join(l.Dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (l *localBlobStore) Put(_ context.Context, key string, data io.Reader, _ int64, _ string) error {
+	filePath := l.filePath(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, data); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (l *localBlobStore) Get(_ context.Context, key string) (*domain.Blob, error) {
+	filePath := l.filePath(key)
+
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		file.Close()
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return &domain.Blob{
+		Body:        file,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(filePath)),
+	}, nil
+}
+
+func (l *localBlobStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(l.filePath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Ping check that directory exists and is writable, directory is created if it does not exist.
+func (l *localBlobStore) Ping(_ context.Context) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(l.Dir, ".ping-*")
+	if err != nil {
+		return err
+	}
+
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"petstore/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalBlobStore(dir)
+
+	if err := store.Put(ctx, "pets/1/photos/2.jpg", strings.NewReader("image"), 5, "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	blob, err := store.Get(ctx, "pets/1/photos/2.jpg")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, err := io.ReadAll(blob.Body)
+	blob.Body.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if string(data) != "image" || blob.Size != 5 || blob.ContentType != "image/jpeg" {
+		t.Errorf("got blob %q, size %d, content type %q", data, blob.Size, blob.ContentType)
+	}
+
+	if err := store.Delete(ctx, "pets/1/photos/2.jpg"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err := store.Get(ctx, "pets/1/photos/2.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("get deleted blob: got %v, want ErrBlobNotFound", err)
+	}
+
+	if err := store.Delete(ctx, "pets/1/photos/2.jpg"); err != nil {
+		t.Errorf("delete missing blob: %v", err)
+	}
+
+	if _, err := store.Get(ctx, "pets/1"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("get directory: got %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestLocalBlobStoreKeyStaysInDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "static")
+	store := NewLocalBlobStore(dir)
+
+	if err := store.Put(ctx, "../../escaped.txt", strings.NewReader("data"), 4, "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); err != nil {
+		t.Errorf("blob is not inside directory: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "escaped.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("blob is written outside directory: %v", err)
+	}
+}
+
+func TestLocalBlobStorePing(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "static")
+	store := NewLocalBlobStore(dir)
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("directory is not created: %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("ping left files: %v", entries)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"petstore/internal/domain"
+)
+
+// s3BlobStore stores blobs as objects in S3 compatible storage (AWS S3, MinIO, etc.).
+type s3BlobStore struct {
+	Client *minio.Client
+	Bucket string
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// NewS3BlobStore connect to storage and create bucket if it does not exist.
+func NewS3BlobStore(ctx context.Context, cfg S3Config) (domain.BlobStore, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &s3BlobStore{Client: client, Bucket: cfg.Bucket}, nil
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, data, size, minio.PutObjectOptions{ContentType: contentType})
+
+	return err
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, key string) (*domain.Blob, error) {
+	// stat first, because GetObject is lazy and does not report missing object
+	info, err := s.Client.StatObject(ctx, s.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, domain.ErrBlobNotFound
+		}
+
+		return nil, err
+	}
+
+	object, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Blob{
+		Body:        object,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+	}, nil
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, key string) error {
+	return s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *s3BlobStore) Ping(ctx context.Context) error {
+	exists, err := s.Client.BucketExists(ctx, s.Bucket)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.Bucket)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"petstore/internal/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is stand-in of S3 compatible storage, it supports path-style requests used by blob store.
+// Signatures are not checked.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{buckets: make(map[string]map[string]fakeObject)}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, bucketExists := f.buckets[bucket]
+
+	if key == "" {
+		switch r.Method {
+		case http.MethodHead:
+			if !bucketExists {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			f.buckets[bucket] = make(map[string]fakeObject)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+
+		return
+	}
+
+	if !bucketExists {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+
+		objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodHead, http.MethodGet:
+		object, ok := objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// readS3Body returns payload of request, aws-chunked body of streaming signature is decoded.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`,
+		code, code)
+}
+
+func newTestS3BlobStore(t *testing.T) (domain.BlobStore, *fakeS3) {
+	t.Helper()
+
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewS3BlobStore(context.Background(), S3Config{
+		Endpoint:  serverURL.Host,
+		Region:    "us-east-1",
+		Bucket:    "petstore",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+
+	return store, fake
+}
+
+func TestS3BlobStoreCreatesBucket(t *testing.T) {
+	store, fake := newTestS3BlobStore(t)
+
+	if _, ok := fake.buckets["petstore"]; !ok {
+		t.Fatal("bucket is not created")
+	}
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Errorf("ping: %v", err)
+	}
+}
+
+func TestS3BlobStore(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestS3BlobStore(t)
+
+	if err := store.Put(ctx, "pets/1/photos/2.jpg", strings.NewReader("image"), 5, "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	blob, err := store.Get(ctx, "pets/1/photos/2.jpg")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, err := io.ReadAll(blob.Body)
+	blob.Body.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if string(data) != "image" || blob.Size != 5 || blob.ContentType != "image/jpeg" {
+		t.Errorf("got blob %q, size %d, content type %q", data, blob.Size, blob.ContentType)
+	}
+
+	if err := store.Delete(ctx, "pets/1/photos/2.jpg"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err := store.Get(ctx, "pets/1/photos/2.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("get deleted blob: got %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestS3BlobStorePingMissingBucket(t *testing.T) {
+	store, fake := newTestS3BlobStore(t)
+
+	fake.mu.Lock()
+	delete(fake.buckets, "petstore")
+	fake.mu.Unlock()
+
+	if err := store.Ping(context.Background()); err == nil {
+		t.Error("ping of missing bucket: got nil error")
+	}
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"petstore/internal/domain"
+	"strings"
+	"time"
+)
+
+// Config is configuration of application.
+// Values are loaded in order, each next source overrides previous one:
+// defaults, env file, environment variables, command line flags.
+type Config struct {
+	Server Server `json:"server"`
+	// Storage is storage of data: "postgres" or "memory", DB is ignored for memory
+	Storage string  `json:"storage"`
+	Memory  Memory  `json:"memory"`
+	DB      DB      `json:"db"`
+	JWT     JWT     `json:"jwt"`
+	Blob    Blob    `json:"blob"`
+	Photo   Photo   `json:"photo"`
+	Log     Log     `json:"log"`
+	Health  Health  `json:"health"`
+	Tracing Tracing `json:"tracing"`
+}
+
+type Server struct {
+	// Addr is address to listen, e.g. ":8080"
+	Addr string `json:"addr"`
+	// PublicURL is address of server for clients, it is used by swagger
+	PublicURL         string        `json:"publicUrl"`
+	ReadTimeout       time.Duration `json:"readTimeout"`
+	ReadHeaderTimeout time.Duration `json:"readHeaderTimeout"`
+	WriteTimeout      time.Duration `json:"writeTimeout"`
+	IdleTimeout       time.Duration `json:"idleTimeout"`
+	// DrainDelay is time between failing readiness and closing listener on shutdown,
+	// so load balancer stops sending new requests
+	DrainDelay time.Duration `json:"drainDelay"`
+	// ShutdownTimeout is max time of waiting for in-flight requests on shutdown
+	ShutdownTimeout time.Duration `json:"shutdownTimeout"`
+}
+
+const (
+	StoragePostgres = "postgres"
+	// StorageMemory keeps data in memory of process, it is lost on restart
+	StorageMemory = "memory"
+)
+
+type Memory struct {
+	// AdminPassword is password of user "admin", which is created on start, user is not created if it is empty
+	AdminPassword Secret `json:"adminPassword"`
+}
+
+type DB struct {
+	// DSN is connection string of postgres, if it is set, Host, Port, User, Password, Name and SSLMode are ignored
+	DSN             Secret        `json:"dsn"`
+	Host            string        `json:"host"`
+	Port            int           `json:"port"`
+	User            string        `json:"user"`
+	Password        Secret        `json:"password"`
+	Name            string        `json:"name"`
+	SSLMode         string        `json:"sslMode"`
+	MaxOpenConns    int           `json:"maxOpenConns"`
+	MaxIdleConns    int           `json:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `json:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `json:"connMaxIdleTime"`
+	// AutoMigrate is true if pending migrations are applied on start of server
+	AutoMigrate bool `json:"autoMigrate"`
+}
+
+// ConnString returns connection string of postgres.
+func (d DB) ConnString() string {
+	if d.DSN != "" {
+		return d.DSN.Value()
+	}
+
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quote(d.Host), d.Port, quote(d.User), quote(d.Password.Value()), quote(d.Name), quote(d.SSLMode))
+}
+
+// quote - quote value of connection string, so it can contain spaces and quotes.
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+type JWT struct {
+	// Algorithm is signature algorithm of tokens, e.g. HS256 or RS256
+	Algorithm string `json:"algorithm"`
+	// Secret is key of HMAC algorithms (HS256, HS384, HS512)
+	Secret Secret `json:"secret"`
+	// PrivateKeyFile is path to PEM private key of asymmetric algorithms (RS*, PS*, ES*, EdDSA)
+	PrivateKeyFile string `json:"privateKeyFile"`
+	// AcceptableSkew is allowed clock skew on token validation
+	AcceptableSkew time.Duration `json:"acceptableSkew"`
+}
+
+// IsHMAC returns true if algorithm uses shared secret instead of key pair.
+func (j JWT) IsHMAC() bool {
+	switch jwa.SignatureAlgorithm(j.Algorithm) {
+	case jwa.HS256, jwa.HS384, jwa.HS512:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	BlobStorageLocal = "local"
+	BlobStorageS3    = "s3"
+)
+
+type Blob struct {
+	// Storage is type of storage of uploaded files: "local" or "s3"
+	Storage  string `json:"storage"`
+	LocalDir string `json:"localDir"`
+	S3       S3     `json:"s3"`
+}
+
+type S3 struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey"`
+	SecretKey Secret `json:"secretKey"`
+	UseSSL    bool   `json:"useSsl"`
+}
+
+type Photo struct {
+	Thumbnails []domain.ThumbnailSize `json:"thumbnails"`
+}
+
+const (
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+)
+
+type Log struct {
+	// Level is minimal level of logs: debug, info, warn or error
+	Level string `json:"level"`
+	// Format is encoding of logs: "json" or "console"
+	Format string `json:"format"`
+}
+
+type Health struct {
+	// Timeout is max duration of check of each component
+	Timeout time.Duration `json:"timeout"`
+}
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+type Tracing struct {
+	// Exporter is destination of spans: "none", "stdout" or "otlp"
+	Exporter string `json:"exporter"`
+	// OTLPEndpoint is host and port of OTLP/HTTP collector, e.g. "localhost:4318"
+	OTLPEndpoint string `json:"otlpEndpoint"`
+	// OTLPInsecure disables TLS of connection to collector
+	OTLPInsecure bool `json:"otlpInsecure"`
+	// SampleRatio is part of traces which are recorded, from 0 to 1
+	SampleRatio float64 `json:"sampleRatio"`
+	ServiceName string  `json:"serviceName"`
+}
+
+// Default returns configuration with default values.
+// Values without defaults, like database credentials and jwt secret, must be set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:              ":8080",
+			PublicURL:         "http://localhost:8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Storage: StoragePostgres,
+		DB: DB{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			AutoMigrate:     true,
+		},
+		JWT: JWT{
+			Algorithm:      string(jwa.HS256),
+			AcceptableSkew: 30 * time.Second,
+		},
+		Blob: Blob{
+			Storage:  BlobStorageLocal,
+			LocalDir: "static",
+		},
+		Photo: Photo{
+			Thumbnails: []domain.ThumbnailSize{
+				{Name: "small", Size: 150},
+				{Name: "medium", Size: 400},
+				{Name: "large", Size: 800},
+			},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: LogFormatJSON,
+		},
+		Health: Health{
+			Timeout: 2 * time.Second,
+		},
+		Tracing: Tracing{
+			Exporter:     TracingExporterNone,
+			OTLPEndpoint: "localhost:4318",
+			SampleRatio:  1,
+			ServiceName:  "petstore",
+		},
+	}
+}
+
+// Validate check that configuration is complete and consistent.
+// It returns all found errors at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server address is not set")
+	check(c.Server.ReadTimeout >= 0, "server read timeout is negative")
+	check(c.Server.ReadHeaderTimeout >= 0, "server read header timeout is negative")
+	check(c.Server.WriteTimeout >= 0, "server write timeout is negative")
+	check(c.Server.IdleTimeout >= 0, "server idle timeout is negative")
+	check(c.Server.DrainDelay >= 0, "server drain delay is negative")
+	check(c.Server.ShutdownTimeout >= 0, "server shutdown timeout is negative")
+
+	switch c.Storage {
+	case StoragePostgres:
+		if c.DB.DSN == "" {
+			check(c.DB.Host != "", "database host is not set")
+			check(c.DB.Port > 0, "database port is invalid: %d", c.DB.Port)
+			check(c.DB.User != "", "database user is not set")
+			check(c.DB.Name != "", "database name is not set")
+		}
+		check(c.DB.MaxOpenConns >= 0, "database max open connections is negative")
+		check(c.DB.MaxIdleConns >= 0, "database max idle connections is negative")
+		check(c.DB.ConnMaxLifetime >= 0, "database connection max lifetime is negative")
+		check(c.DB.ConnMaxIdleTime >= 0, "database connection max idle time is negative")
+	case StorageMemory:
+		// bcrypt does not hash passwords longer than 72 bytes
+		check(len(c.Memory.AdminPassword) <= 72, "memory admin password is longer than 72 bytes")
+	default:
+		errs = append(errs, fmt.Errorf("unknown storage: %s", c.Storage))
+	}
+
+	var alg jwa.SignatureAlgorithm
+	if err := alg.Accept(c.JWT.Algorithm); err != nil || alg == jwa.NoSignature {
+		errs = append(errs, fmt.Errorf("unsupported jwt algorithm: %s", c.JWT.Algorithm))
+	} else if c.JWT.IsHMAC() {
+		check(c.JWT.Secret != "", "jwt secret is not set")
+	} else if c.JWT.PrivateKeyFile == "" {
+		errs = append(errs, fmt.Errorf("jwt private key file is not set"))
+	} else if _, err := os.Stat(c.JWT.PrivateKeyFile); err != nil {
+		errs = append(errs, fmt.Errorf("jwt private key file: %w", err))
+	}
+	check(c.JWT.AcceptableSkew >= 0, "jwt acceptable skew is negative")
+
+	switch c.Blob.Storage {
+	case BlobStorageLocal:
+		check(c.Blob.LocalDir != "", "blob local dir is not set")
+	case BlobStorageS3:
+		check(c.Blob.S3.Endpoint != "", "s3 endpoint is not set")
+		check(c.Blob.S3.Bucket != "", "s3 bucket is not set")
+	default:
+		errs = append(errs, fmt.Errorf("unknown blob storage: %s", c.Blob.Storage))
+	}
+
+	check(len(c.Photo.Thumbnails) > 0, "photo thumbnails are not set")
+
+	_, err := zapcore.ParseLevel(c.Log.Level)
+	check(err == nil, "unknown log level: %s", c.Log.Level)
+	check(c.Log.Format == LogFormatJSON || c.Log.Format == LogFormatConsole, "unknown log format: %s", c.Log.Format)
+
+	check(c.Health.Timeout > 0, "health check timeout must be positive")
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		check(c.Tracing.OTLPEndpoint != "", "tracing otlp endpoint is not set")
+	default:
+		errs = append(errs, fmt.Errorf("unknown tracing exporter: %s", c.Tracing.Exporter))
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing sample ratio must be from 0 to 1: %v", c.Tracing.SampleRatio)
+	check(c.Tracing.ServiceName != "", "tracing service name is not set")
+
+	return errors.Join(errs...)
+}
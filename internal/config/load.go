@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"petstore/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultEnvFile = ".env"
+
+// option is single configuration value.
+// It is read from environment variable name and from flag with lower case name and dashes,
+// e.g. DB_HOST and --db-host.
+type option struct {
+	name  string
+	usage string
+	parse func(value string) error
+}
+
+func (c *Config) options() []option {
+	return []option{
+		{"SERVER_ADDR", "address to listen", stringVar(&c.Server.Addr)},
+		{"SERVER_PUBLIC_URL", "address of server for clients", stringVar(&c.Server.PublicURL)},
+		{"SERVER_READ_TIMEOUT", "timeout of reading request", durationVar(&c.Server.ReadTimeout)},
+		{"SERVER_READ_HEADER_TIMEOUT", "timeout of reading request headers", durationVar(&c.Server.ReadHeaderTimeout)},
+		{"SERVER_WRITE_TIMEOUT", "timeout of writing response", durationVar(&c.Server.WriteTimeout)},
+		{"SERVER_IDLE_TIMEOUT", "timeout of idle keep-alive connection", durationVar(&c.Server.IdleTimeout)},
+		{"SERVER_DRAIN_DELAY", "time between failing readiness and closing listener on shutdown",
+			durationVar(&c.Server.DrainDelay)},
+		{"SERVER_SHUTDOWN_TIMEOUT", "max time of waiting for in-flight requests on shutdown",
+			durationVar(&c.Server.ShutdownTimeout)},
+
+		{"STORAGE", "storage of data: postgres or memory, memory is lost on restart", stringVar(&c.Storage)},
+		{"MEMORY_ADMIN_PASSWORD", "password of user admin, which is created in memory storage on start",
+			secretVar(&c.Memory.AdminPassword)},
+
+		{"DB_DSN", "connection string of postgres, overrides other DB_* connection options", secretVar(&c.DB.DSN)},
+		{"DB_HOST", "database host", stringVar(&c.DB.Host)},
+		{"DB_PORT", "database port", intVar(&c.DB.Port)},
+		{"DB_USER", "database user", stringVar(&c.DB.User)},
+		{"DB_PASSWORD", "database password", secretVar(&c.DB.Password)},
+		{"DB_NAME", "database name", stringVar(&c.DB.Name)},
+		{"DB_SSLMODE", "database ssl mode", stringVar(&c.DB.SSLMode)},
+		{"DB_MAX_OPEN_CONNS", "max open connections to database, 0 is unlimited", intVar(&c.DB.MaxOpenConns)},
+		{"DB_MAX_IDLE_CONNS", "max idle connections to database", intVar(&c.DB.MaxIdleConns)},
+		{"DB_CONN_MAX_LIFETIME", "max lifetime of database connection, 0 is unlimited", durationVar(&c.DB.ConnMaxLifetime)},
+		{"DB_CONN_MAX_IDLE_TIME", "max idle time of database connection, 0 is unlimited", durationVar(&c.DB.ConnMaxIdleTime)},
+		{"DB_AUTO_MIGRATE", "apply pending migrations on start of server", boolVar(&c.DB.AutoMigrate)},
+
+		{"JWT_ALGORITHM", "signature algorithm of tokens", stringVar(&c.JWT.Algorithm)},
+		{"JWT_SECRET", "secret of HMAC algorithms", secretVar(&c.JWT.Secret)},
+		{"JWT_PRIVATE_KEY_FILE", "path to PEM private key of asymmetric algorithms", stringVar(&c.JWT.PrivateKeyFile)},
+		{"JWT_ACCEPTABLE_SKEW", "allowed clock skew on token validation", durationVar(&c.JWT.AcceptableSkew)},
+
+		{"BLOB_STORAGE", "storage of uploaded files: local or s3", stringVar(&c.Blob.Storage)},
+		{"BLOB_LOCAL_DIR", "directory of local storage", stringVar(&c.Blob.LocalDir)},
+		{"S3_ENDPOINT", "s3 endpoint", stringVar(&c.Blob.S3.Endpoint)},
+		{"S3_REGION", "s3 region", stringVar(&c.Blob.S3.Region)},
+		{"S3_BUCKET", "s3 bucket", stringVar(&c.Blob.S3.Bucket)},
+		{"S3_ACCESS_KEY", "s3 access key", stringVar(&c.Blob.S3.AccessKey)},
+		{"S3_SECRET_KEY", "s3 secret key", secretVar(&c.Blob.S3.SecretKey)},
+		{"S3_USE_SSL", "use https for s3", boolVar(&c.Blob.S3.UseSSL)},
+
+		{"PHOTO_THUMBNAILS", "thumbnails of photos in format name:size,name:size", thumbnailsVar(&c.Photo.Thumbnails)},
+
+		{"LOG_LEVEL", "minimal level of logs: debug, info, warn or error", stringVar(&c.Log.Level)},
+		{"LOG_FORMAT", "encoding of logs: json or console", stringVar(&c.Log.Format)},
+
+		{"HEALTH_CHECK_TIMEOUT", "max duration of health check of each component", durationVar(&c.Health.Timeout)},
+
+		{"TRACING_EXPORTER", "destination of spans: none, stdout or otlp", stringVar(&c.Tracing.Exporter)},
+		{"TRACING_OTLP_ENDPOINT", "host and port of OTLP/HTTP collector", stringVar(&c.Tracing.OTLPEndpoint)},
+		{"TRACING_OTLP_INSECURE", "disable TLS of connection to collector", boolVar(&c.Tracing.OTLPInsecure)},
+		{"TRACING_SAMPLE_RATIO", "part of traces which are recorded, from 0 to 1", floatVar(&c.Tracing.SampleRatio)},
+		{"TRACING_SERVICE_NAME", "service name in traces", stringVar(&c.Tracing.ServiceName)},
+	}
+}
+
+// Load returns validated configuration.
+// args are command line arguments without program name.
+// Env file is set by --config flag, by default .env is loaded if it exists.
+// Returns flag.ErrHelp if help is requested.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	options := cfg.options()
+
+	fs := flag.NewFlagSet("petstore", flag.ContinueOnError)
+	envFile := fs.String("config", "", "path to env file (default .env if it exists)")
+
+	flagValues := make(map[string]string)
+	for _, opt := range options {
+		name := opt.name
+		fs.Func(flagName(name), opt.usage+" (env "+name+")", func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := loadEnvFile(*envFile); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, opt := range options {
+		if value, ok := os.LookupEnv(opt.name); ok {
+			if err := opt.parse(value); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", opt.name, err))
+			}
+		}
+
+		if value, ok := flagValues[opt.name]; ok {
+			if err := opt.parse(value); err != nil {
+				errs = append(errs, fmt.Errorf("flag --%s: %w", flagName(opt.name), err))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadEnvFile - set environment variables from env file, existing variables are not overridden.
+// Missing default file is ignored, missing file set by user is error.
+func loadEnvFile(path string) error {
+	if path == "" {
+		if _, err := os.Stat(defaultEnvFile); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		path = defaultEnvFile
+	}
+
+	if err := godotenv.Load(path); err != nil {
+		return fmt.Errorf("failed load env file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+func stringVar(p *string) func(string) error {
+	return func(value string) error {
+		*p = value
+		return nil
+	}
+}
+
+func secretVar(p *Secret) func(string) error {
+	return func(value string) error {
+		*p = Secret(value)
+		return nil
+	}
+}
+
+func intVar(p *int) func(string) error {
+	return func(value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		*p = v
+		return nil
+	}
+}
+
+func boolVar(p *bool) func(string) error {
+	return func(value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		*p = v
+		return nil
+	}
+}
+
+func floatVar(p *float64) func(string) error {
+	return func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		*p = v
+		return nil
+	}
+}
+
+func durationVar(p *time.Duration) func(string) error {
+	return func(value string) error {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		*p = v
+		return nil
+	}
+}
+
+// thumbnailsVar - parse thumbnails in format "name:size,name:size".
+func thumbnailsVar(p *[]domain.ThumbnailSize) func(string) error {
+	return func(value string) error {
+		thumbnails := make([]domain.ThumbnailSize, 0)
+		for _, thumbnail := range strings.Split(value, ",") {
+			name, size, found := strings.Cut(strings.TrimSpace(thumbnail), ":")
+			sizeInt, err := strconv.Atoi(size)
+			if !found || name == "" || err != nil || sizeInt <= 0 {
+				return fmt.Errorf("invalid thumbnail size: %s", thumbnail)
+			}
+
+			thumbnails = append(thumbnails, domain.ThumbnailSize{Name: name, Size: sizeInt})
+		}
+
+		*p = thumbnails
+		return nil
+	}
+}
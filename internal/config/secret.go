@@ -0,0 +1,30 @@
+package config
+
+const redacted = "[REDACTED]"
+
+// Secret is string which is hidden when it is printed or logged.
+// Use Value to get real value.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String returns redacted value, empty secret stays empty, so it is visible that secret is not set.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// GoString hides secret in %#v formatting.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalText hides secret in json and logs.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
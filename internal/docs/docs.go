@@ -15,6 +15,126 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/category": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "List categories of pets",
+                "parameters": [
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of categories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Category"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks database, migrations and blob storage. Returns 503 if any critical component is down.\nComponents are returned only to staff and admin, others get only status.",
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health of application",
+                "responses": {
+                    "200": {
+                        "description": "Up or degraded",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Health"
+                        }
+                    },
+                    "503": {
+                        "description": "Down",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Health"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness of application",
+                "responses": {
+                    "200": {
+                        "description": "Alive",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/pet": {
             "put": {
                 "security": [
@@ -23,10 +143,12 @@ const docTemplate = `{
                     }
                 ],
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "pet"
@@ -56,11 +178,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "Pet not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Status of pet with active order can not be changed",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             },
@@ -71,10 +205,12 @@ const docTemplate = `{
                     }
                 ],
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "pet"
@@ -103,6 +239,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -115,12 +257,13 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "pet"
                 ],
-                "summary": "Delete a pet by ID",
+                "summary": "Finds pets by status",
                 "parameters": [
                     {
                         "type": "string",
@@ -128,6 +271,38 @@ const docTemplate = `{
                         "name": "status",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -155,6 +330,96 @@ const docTemplate = `{
                 }
             }
         },
+        "/pet/findByTags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "Finds pets by tags",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tags to filter by, comma separated or repeated",
+                        "name": "tags",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Match any of tags or all of them",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pets found by tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Pet"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/pet/{petId}": {
             "get": {
                 "security": [
@@ -163,7 +428,8 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "pet"
@@ -206,7 +472,8 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "pet"
@@ -234,16 +501,242 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "Pet not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Pet has orders",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/pet/{petId}/uploadImage": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "Uploads an image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of pet to update",
+                        "name": "petId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image to upload: jpeg, png, webp or gif",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Public URL of uploaded image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Image is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Like /healthz, but also returns 503 when application drains requests before shutdown.\nOnly status is returned.",
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness of application",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Health"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Health"
+                        }
+                    }
+                }
+            }
+        },
+        "/store/inventory": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "Returns pet inventories by status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Add breakdown by category",
+                        "name": "byCategory",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of pets by status",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Inventory"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
         "/store/order": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Staff get orders of all users, other users get only own orders.",
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "List orders",
+                "parameters": [
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
@@ -251,10 +744,12 @@ const docTemplate = `{
                     }
                 ],
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "store"
@@ -283,6 +778,18 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Pet is not available",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -294,8 +801,10 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Users can get only own orders, staff can get all orders.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "store"
@@ -323,6 +832,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "Order not found",
                         "schema": {
@@ -337,8 +852,10 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Users can delete only own orders, staff can delete all orders.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "store"
@@ -347,17 +864,237 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID of order to delete",
-                        "name": "orderId",
-                        "in": "path",
-                        "required": true
+                        "description": "ID of order to delete",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/store/order/{orderId}/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Allowed transitions: placed -\u003e approved, cancelled; approved -\u003e delivered, cancelled; delivered -\u003e returned.\nUsers can only cancel own orders, staff can move all orders.",
+                "consumes": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "Update status of order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of order to update",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status of order",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated order",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition is not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/store/orders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "List my orders",
+                "parameters": [
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "List tags of pets",
+                "parameters": [
+                    {
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Order deleted",
+                        "description": "Page of tags",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Tag"
+                            }
                         }
                     },
                     "400": {
@@ -365,44 +1102,68 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
-                    },
-                    "404": {
-                        "description": "Order not found",
-                        "schema": {
-                            "type": "string"
-                        }
                     }
                 }
             }
         },
         "/user": {
-            "put": {
-                "consumes": [
-                    "application/json"
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Only for admin.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Update a user with form data",
+                "summary": "List users",
                 "parameters": [
                     {
-                        "description": "User object that needs to update",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.User"
-                        }
+                        "maximum": 1000000,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starts from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields, prefix - for descending order, e.g. -name,id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User updated",
+                        "description": "Page of users",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.UserOutput"
+                            }
                         }
                     },
                     "400": {
@@ -411,8 +1172,8 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "string"
                         }
@@ -420,11 +1181,14 @@ const docTemplate = `{
                 }
             },
             "post": {
+                "description": "New user is always customer.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -433,11 +1197,11 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "description": "User to add to the store",
-                        "name": "pet",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.UserInput"
                         }
                     }
                 ],
@@ -453,17 +1217,31 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Username is taken",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
         "/user/createWithList": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Only for admin, users can have any role.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -478,7 +1256,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.User"
+                                "$ref": "#/definitions/domain.UserInput"
                             }
                         }
                     }
@@ -496,11 +1274,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "User not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Username is taken",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -508,10 +1298,12 @@ const docTemplate = `{
         "/user/login": {
             "post": {
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -541,8 +1333,8 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Invalid credentials",
                         "schema": {
                             "type": "string"
                         }
@@ -558,10 +1350,12 @@ const docTemplate = `{
                     }
                 ],
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -579,14 +1373,27 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
         "/user/{username}": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Email, phone, status and role are visible only to user itself and admin.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -605,7 +1412,65 @@ const docTemplate = `{
                     "200": {
                         "description": "Find user by Username",
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.UserOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Users can update only themselves, role can be changed only by admin.",
+                "consumes": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a user with form data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username of user to update",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User object that needs to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -614,17 +1479,36 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "User not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Username is taken",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Users can delete only themselves, except admin.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml"
                 ],
                 "tags": [
                     "user"
@@ -652,11 +1536,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "User not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "User has orders",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -665,6 +1561,10 @@ const docTemplate = `{
     "definitions": {
         "controller.LoginRequest": {
             "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
             "properties": {
                 "password": {
                     "type": "string"
@@ -674,19 +1574,121 @@ const docTemplate = `{
                 }
             }
         },
+        "controller.UpdateStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "placed",
+                        "approved",
+                        "delivered",
+                        "cancelled",
+                        "returned"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.OrderStatus"
+                        }
+                    ]
+                }
+            }
+        },
         "domain.Category": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "id": {
                     "type": "integer"
                 },
                 "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "domain.ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "description": "Critical is true, if application can not serve requests without component",
+                    "type": "boolean"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency": {
+                    "description": "Latency is duration of check, e.g. \"1.5ms\"",
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.HealthStatus"
+                }
+            }
+        },
+        "domain.Health": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.ComponentHealth"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.HealthStatus"
+                }
+            }
+        },
+        "domain.HealthStatus": {
+            "type": "string",
+            "enum": [
+                "up",
+                "degraded",
+                "down",
+                "draining"
+            ],
+            "x-enum-varnames": [
+                "HealthStatusUp",
+                "HealthStatusDegraded",
+                "HealthStatusDown",
+                "HealthStatusDraining"
+            ]
+        },
+        "domain.Inventory": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "description": "Categories is number of pets by status in each category, map key is category name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "statuses": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 }
             }
         },
         "domain.Order": {
             "type": "object",
+            "required": [
+                "petId"
+            ],
             "properties": {
                 "complete": {
                     "type": "boolean"
@@ -695,13 +1697,18 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "petId": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 1
                 },
                 "shipDate": {
                     "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/domain.OrderStatus"
+                },
+                "userId": {
+                    "description": "UserId is id of user who placed order",
+                    "type": "integer"
                 }
             }
         },
@@ -710,16 +1717,25 @@ const docTemplate = `{
             "enum": [
                 "placed",
                 "approved",
-                "delivered"
+                "delivered",
+                "cancelled",
+                "returned"
             ],
             "x-enum-varnames": [
                 "PlacedOrderStatus",
                 "ApprovedOrderStatus",
-                "DeliveredOrderStatus"
+                "DeliveredOrderStatus",
+                "CancelledOrderStatus",
+                "ReturnedOrderStatus"
             ]
         },
         "domain.Pet": {
             "type": "object",
+            "required": [
+                "category",
+                "name",
+                "status"
+            ],
             "properties": {
                 "category": {
                     "$ref": "#/definitions/domain.Category"
@@ -728,7 +1744,8 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "photoUrls": {
                     "type": "array",
@@ -736,8 +1753,24 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "photos": {
+                    "description": "Photos contains renditions of each photo, in the same order as PhotoUrls",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Photo"
+                    }
+                },
                 "status": {
-                    "$ref": "#/definitions/domain.PetStatus"
+                    "enum": [
+                        "available",
+                        "pending",
+                        "sold"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PetStatus"
+                        }
+                    ]
                 },
                 "tags": {
                     "type": "array",
@@ -756,21 +1789,103 @@ const docTemplate = `{
                 "PetStatusAvailable"
             ]
         },
+        "domain.Photo": {
+            "type": "object",
+            "properties": {
+                "thumbnails": {
+                    "description": "Thumbnails is map of thumbnail name to URL, e.g. \"small\": \"/static/pets/1/photos/1_small.jpg\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "staff",
+                "customer"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleStaff",
+                "RoleCustomer"
+            ]
+        },
         "domain.Tag": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "id": {
                     "type": "integer"
                 },
                 "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "domain.UserInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "firstName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "lastName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "password": {
+                    "description": "Password is limited by bcrypt, which does not hash passwords longer than 72 bytes",
                     "type": "string"
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "role": {
+                    "description": "Role is ignored, except when it is set by admin",
+                    "enum": [
+                        "admin",
+                        "staff",
+                        "customer"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    ]
+                },
+                "userStatus": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
-        "domain.User": {
+        "domain.UserOutput": {
             "type": "object",
             "properties": {
                 "email": {
+                    "description": "Email is visible only to user itself and admin",
                     "type": "string"
                 },
                 "firstName": {
@@ -782,13 +1897,20 @@ const docTemplate = `{
                 "lastName": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
-                },
                 "phone": {
+                    "description": "Phone is visible only to user itself and admin",
                     "type": "string"
                 },
+                "role": {
+                    "description": "Role is visible only to user itself and admin",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    ]
+                },
                 "userStatus": {
+                    "description": "UserStatus is visible only to user itself and admin",
                     "type": "integer"
                 },
                 "username": {
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+var ErrBlobNotFound = NewNotFoundError("blob_not_found", "blob not found")
+
+type Blob struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// BlobStore stores files by key.
+// Key is slash separated path, e.g. "pets/1/photos/2.jpg".
+type BlobStore interface {
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	// Get returns blob by key, caller must close Blob.Body.
+	Get(ctx context.Context, key string) (*Blob, error)
+	Delete(ctx context.Context, key string) error
+	// Ping returns error if storage is not available for writing.
+	Ping(ctx context.Context) error
+}
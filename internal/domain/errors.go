@@ -0,0 +1,126 @@
+package domain
+
+import "errors"
+
+// ErrorKind is category of domain error, it defines how error is shown to client.
+type ErrorKind int
+
+const (
+	// KindInternal is kind of unexpected errors, e.g. errors of database
+	KindInternal ErrorKind = iota
+	// KindNotFound - requested entity does not exist or is hidden from principal
+	KindNotFound
+	// KindConflict - request conflicts with current state, e.g. pet is already sold
+	KindConflict
+	// KindValidation - request has invalid values
+	KindValidation
+	// KindUnauthorized - principal is not authenticated or credentials are wrong
+	KindUnauthorized
+	// KindForbidden - principal has no permission
+	KindForbidden
+)
+
+// CodeInvalidField is code of validation errors of fields without own code.
+const CodeInvalidField = "invalid_field"
+
+// ErrValidation is cause of errors of request body, which has invalid fields.
+var ErrValidation = NewValidationError("validation_failed", "validation failed")
+
+// ErrInvalidBody is cause of errors of request body, which can not be decoded.
+var ErrInvalidBody = NewValidationError("invalid_body", "invalid request body")
+
+// Violation is invalid field of request, field is name of param or json path of body.
+type Violation struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Error is error with kind. Errors wrapped by fmt.Errorf with %w keep kind of wrapped error.
+type Error struct {
+	Kind ErrorKind
+	// Code is stable machine readable code of error, e.g. "pet_not_found"
+	Code       string
+	Message    string
+	Violations []Violation
+	// Err is cause of error, it is checked by errors.Is
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NewError(kind ErrorKind, code string, message string) error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NewNotFoundError(code string, message string) error {
+	return NewError(KindNotFound, code, message)
+}
+
+func NewConflictError(code string, message string) error {
+	return NewError(KindConflict, code, message)
+}
+
+func NewValidationError(code string, message string) error {
+	return NewError(KindValidation, code, message)
+}
+
+func NewUnauthorizedError(code string, message string) error {
+	return NewError(KindUnauthorized, code, message)
+}
+
+func NewForbiddenError(code string, message string) error {
+	return NewError(KindForbidden, code, message)
+}
+
+// FieldError returns validation error of field with message and code of err.
+// Errors without code get CodeInvalidField.
+func FieldError(field string, err error) error {
+	code := CodeOf(err)
+	if code == "" {
+		code = CodeInvalidField
+	}
+
+	return &Error{
+		Kind:       KindValidation,
+		Code:       code,
+		Message:    err.Error(),
+		Violations: []Violation{{Field: field, Message: err.Error()}},
+		Err:        err,
+	}
+}
+
+// KindOf returns kind of error, errors without kind are internal.
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+
+	return KindInternal
+}
+
+// CodeOf returns code of error, it is empty for errors without code.
+func CodeOf(err error) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+
+	return ""
+}
+
+// ViolationsOf returns invalid fields of error.
+func ViolationsOf(err error) []Violation {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Violations
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusUp HealthStatus = "up"
+	// HealthStatusDegraded - optional component is down, application still serves requests
+	HealthStatusDegraded HealthStatus = "degraded"
+	HealthStatusDown     HealthStatus = "down"
+	// HealthStatusDraining - application is shutting down and does not accept new requests
+	HealthStatusDraining HealthStatus = "draining"
+)
+
+// Health is report of state of application and its components.
+type Health struct {
+	Status     HealthStatus                `json:"status"`
+	Components map[string]*ComponentHealth `json:"components,omitempty"`
+}
+
+// Summary returns report without components, details and errors of components are not public.
+func (h *Health) Summary() *Health {
+	return &Health{Status: h.Status}
+}
+
+type ComponentHealth struct {
+	Status HealthStatus `json:"status"`
+	// Critical is true, if application can not serve requests without component
+	Critical bool `json:"critical"`
+	// Latency is duration of check, e.g. "1.5ms"
+	Latency string                 `json:"latency"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthChecker checks state of dependency of application.
+type HealthChecker interface {
+	// Check returns error if dependency is not available, details are added to report in any case.
+	Check(ctx context.Context) (map[string]interface{}, error)
+}
+
+// HealthCheckerFunc adapts function to HealthChecker.
+type HealthCheckerFunc func(ctx context.Context) (map[string]interface{}, error)
+
+func (f HealthCheckerFunc) Check(ctx context.Context) (map[string]interface{}, error) {
+	return f(ctx)
+}
+
+type HealthUsecase interface {
+	// Check run all checks in parallel, each check is limited by timeout.
+	// Status is down if any critical component is down, degraded if any other component is down.
+	Check(ctx context.Context) *Health
+}
@@ -0,0 +1,42 @@
+package domain
+
+var ErrInvalidListParams = NewValidationError("invalid_list_params", "invalid list params")
+
+type FilterOperator string
+
+const (
+	FilterEq   FilterOperator = "eq"
+	FilterNe   FilterOperator = "ne"
+	FilterLt   FilterOperator = "lt"
+	FilterLte  FilterOperator = "lte"
+	FilterGt   FilterOperator = "gt"
+	FilterGte  FilterOperator = "gte"
+	FilterLike FilterOperator = "like"
+	FilterIn   FilterOperator = "in"
+)
+
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	// Values contains one value, except FilterIn
+	Values []string
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListParams are params of list query.
+// Fields in Filters and Sort are public (json) names of fields.
+type ListParams struct {
+	// Page starts from 1
+	Page    int
+	Limit   int
+	Sort    []SortField
+	Filters []Filter
+}
+
+func (l *ListParams) Offset() int {
+	return (l.Page - 1) * l.Limit
+}
@@ -2,36 +2,89 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"encoding/xml"
 	"time"
 )
 
 type OrderStatus string
 
-var ErrOrderNotFound = errors.New("order not found")
+var ErrOrderNotFound = NewNotFoundError("order_not_found", "order not found")
+var ErrOrderTransition = NewConflictError("order_transition_not_allowed", "order status transition is not allowed")
 
 const (
 	PlacedOrderStatus    OrderStatus = "placed"
 	ApprovedOrderStatus  OrderStatus = "approved"
 	DeliveredOrderStatus OrderStatus = "delivered"
+	CancelledOrderStatus OrderStatus = "cancelled"
+	ReturnedOrderStatus  OrderStatus = "returned"
 )
 
+// ActiveOrderStatuses are statuses of orders, which reserve pet.
+var ActiveOrderStatuses = []OrderStatus{PlacedOrderStatus, ApprovedOrderStatus}
+
+var OrderStatuses = []OrderStatus{PlacedOrderStatus, ApprovedOrderStatus, DeliveredOrderStatus, CancelledOrderStatus,
+	ReturnedOrderStatus}
+
 type Order struct {
-	Id       int         `json:"id"`
-	PetId    int         `json:"petId"`
-	ShipDate time.Time   `json:"shipDate"`
-	Status   OrderStatus `json:"status"`
-	Complete bool        `json:"complete"`
+	XMLName xml.Name `json:"-" xml:"Order"`
+	Id      int      `json:"id" xml:"id"`
+	// UserId is id of user who placed order
+	UserId   int         `json:"userId" xml:"userId"`
+	PetId    int         `json:"petId" xml:"petId" validate:"required,min=1"`
+	ShipDate time.Time   `json:"shipDate" xml:"shipDate"`
+	Status   OrderStatus `json:"status" xml:"status"`
+	Complete bool        `json:"complete" xml:"complete"`
+}
+
+// Inventory is number of pets by status.
+type Inventory struct {
+	Statuses map[PetStatus]int `json:"statuses"`
+	// Categories is number of pets by status in each category, map key is category name
+	Categories map[string]map[PetStatus]int `json:"categories,omitempty"`
 }
 
 type OrderUsecase interface {
-	Get(ctx context.Context, id int) (*Order, error)
+	// Get returns order of principal.
+	// Return ErrForbidden if order belongs to another user, except principal with PermissionOrderReadAll.
+	Get(ctx context.Context, id int, principal *Principal) (*Order, error)
 	Create(ctx context.Context, order *Order) error
-	Delete(ctx context.Context, id int) error
+	// Delete delete order of principal.
+	// Return ErrForbidden if order belongs to another user, except principal with PermissionOrderManage.
+	Delete(ctx context.Context, id int, principal *Principal) error
+	// ListByUser returns page of orders of user and total number of them.
+	ListByUser(ctx context.Context, userId int, params *ListParams) ([]*Order, int, error)
+	// UpdateStatus move order to status.
+	// Principal with PermissionOrderManage can move any order, owner of order can only cancel it,
+	// else return ErrForbidden.
+	// Return ErrOrderTransition if order can not be moved from current status.
+	UpdateStatus(ctx context.Context, id int, status OrderStatus, principal *Principal) (*Order, error)
+	// List returns page of orders and total number of orders.
+	// Principal without PermissionOrderReadAll sees only own orders.
+	List(ctx context.Context, params *ListParams, principal *Principal) ([]*Order, int, error)
+	// GetInventory returns number of pets by status, with breakdown by category if byCategory is true.
+	GetInventory(ctx context.Context, byCategory bool) (*Inventory, error)
 }
 
 type OrderRepository interface {
 	Get(ctx context.Context, id int) (*Order, error)
+	// GetForUpdate get order and lock it until end of transaction.
+	GetForUpdate(ctx context.Context, id int) (*Order, error)
 	Create(ctx context.Context, order *Order) error
+	UpdateStatus(ctx context.Context, id int, status OrderStatus, complete bool) error
 	Delete(ctx context.Context, id int) error
+	// List returns page of orders and total number of orders.
+	List(ctx context.Context, params *ListParams) ([]*Order, int, error)
+	// ListByUser returns page of orders of user and total number of them.
+	ListByUser(ctx context.Context, userId int, params *ListParams) ([]*Order, int, error)
+	// HasActiveByPet returns true if pet has order in one of ActiveOrderStatuses.
+	HasActiveByPet(ctx context.Context, petId int) (bool, error)
+}
+
+func OrderStatusFromString(status string) (OrderStatus, error) {
+	switch OrderStatus(status) {
+	case PlacedOrderStatus, ApprovedOrderStatus, DeliveredOrderStatus, CancelledOrderStatus, ReturnedOrderStatus:
+		return OrderStatus(status), nil
+	default:
+		return PlacedOrderStatus, NewValidationError("invalid_order_status", "invalid order status")
+	}
 }
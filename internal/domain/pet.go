@@ -1,18 +1,25 @@
 package domain
 
-// # TODO add image upload
-
 import (
 	"context"
-	"errors"
+	"encoding/xml"
 	"fmt"
+	"io"
 )
 
 type PetStatus string
 
-var ErrPetNotFound = errors.New("pet not found")
-var ErrCategoryNotFound = errors.New("category not found")
-var ErrTagNotFound = errors.New("tag not found")
+var ErrPetNotFound = NewNotFoundError("pet_not_found", "pet not found")
+var ErrCategoryNotFound = NewNotFoundError("category_not_found", "category not found")
+var ErrTagNotFound = NewNotFoundError("tag_not_found", "tag not found")
+var ErrPetNotAvailable = NewConflictError("pet_not_available", "pet is not available")
+
+// ErrPetHasOrders is returned on delete of pet, which is referenced by orders.
+var ErrPetHasOrders = NewConflictError("pet_has_orders", "pet has orders")
+
+// ErrPetHasActiveOrder is returned on change of status of pet, which is reserved by placed or approved order.
+var ErrPetHasActiveOrder = NewConflictError("pet_has_active_order", "status of pet with active order can not be changed")
+var ErrInvalidImage = NewValidationError("invalid_image", "invalid image, expected jpeg, png, webp or gif")
 
 const (
 	PetStatusAvailable PetStatus = "available"
@@ -20,13 +27,34 @@ const (
 	PetStatusSold                = "sold"
 )
 
+var PetStatuses = []PetStatus{PetStatusAvailable, PetStatusPending, PetStatusSold}
+
+// TagMatch is mode of filtering pets by tags.
+type TagMatch string
+
+const (
+	// TagMatchAny - pet has at least one of tags
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll - pet has all tags
+	TagMatchAll TagMatch = "all"
+)
+
 type Pet struct {
-	Id        int       `json:"id"`
-	Category  *Category `json:"category"`
-	Name      string    `json:"name"`
-	Tags      []*Tag    `json:"tags"`
-	Status    PetStatus `json:"status"`
-	PhotoUrls []string  `json:"photoUrls"`
+	XMLName   xml.Name  `json:"-" xml:"Pet"`
+	Id        int       `json:"id" xml:"id"`
+	Category  *Category `json:"category" xml:"Category" validate:"required"`
+	Name      string    `json:"name" xml:"name" validate:"required,max=255"`
+	Tags      []*Tag    `json:"tags" xml:"tags>Tag"`
+	Status    PetStatus `json:"status" xml:"status" validate:"required,oneof=available pending sold"`
+	PhotoUrls []string  `json:"photoUrls" xml:"photoUrls>photoUrl"`
+	// Photos contains renditions of each photo, in the same order as PhotoUrls
+	Photos []*Photo `json:"photos" xml:"photos>photo"`
+}
+
+type Photo struct {
+	Url string `json:"url"`
+	// Thumbnails is map of thumbnail name to URL, e.g. "small": "/static/pets/1/photos/1_small.jpg"
+	Thumbnails map[string]string `json:"thumbnails"`
 }
 
 type PetDTO struct {
@@ -37,13 +65,15 @@ type PetDTO struct {
 }
 
 type Category struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	XMLName xml.Name `json:"-" xml:"Category"`
+	Id      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name" validate:"required,max=255"`
 }
 
 type Tag struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	XMLName xml.Name `json:"-" xml:"Tag"`
+	Id      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name" validate:"required,max=255"`
 }
 
 type PhotoDTO struct {
@@ -51,30 +81,80 @@ type PhotoDTO struct {
 	PetId int `json:"pet_id"`
 }
 
+// ThumbnailSize is max width and height of thumbnail in pixels.
+type ThumbnailSize struct {
+	Name string
+	Size int
+}
+
+// ProcessedImage is image prepared for storing.
+// All images are jpeg without metadata.
+type ProcessedImage struct {
+	Original []byte
+	// Thumbnails is map of thumbnail name to image
+	Thumbnails map[string][]byte
+}
+
+type ImageProcessor interface {
+	// Process decode image, strip metadata and make thumbnails.
+	// Return ErrInvalidImage if image can not be decoded.
+	Process(data []byte) (*ProcessedImage, error)
+	Thumbnails() []ThumbnailSize
+}
+
 type PetUsecase interface {
 	Get(ctx context.Context, id int) (*Pet, error)
 	Create(ctx context.Context, pet *Pet) error
 	Update(ctx context.Context, pet *Pet) error
 	Delete(ctx context.Context, id int) error
 
-	UploadImage(ctx context.Context, photo *PhotoDTO) error
+	// UploadImage store image as new photo of pet.
+	// Photo Id is set after upload.
+	UploadImage(ctx context.Context, photo *PhotoDTO, image io.Reader) error
+
+	// GetByStatus returns page of pets and total number of pets.
+	GetByStatus(ctx context.Context, status PetStatus, params *ListParams) ([]*Pet, int, error)
+	// GetByTags returns page of pets and total number of pets.
+	GetByTags(ctx context.Context, tags []string, match TagMatch, params *ListParams) ([]*Pet, int, error)
 
-	GetByStatus(ctx context.Context, status PetStatus) ([]*Pet, error)
+	ListCategories(ctx context.Context, params *ListParams) ([]*Category, int, error)
+	ListTags(ctx context.Context, params *ListParams) ([]*Tag, int, error)
 }
 
 type PetRepository interface {
 	Get(ctx context.Context, id int) (*PetDTO, error)
+	// GetForUpdate get pet and lock it until end of transaction.
+	GetForUpdate(ctx context.Context, id int) (*PetDTO, error)
 	Create(ctx context.Context, pet *PetDTO) error
 	Update(ctx context.Context, pet *PetDTO) error
 	Delete(ctx context.Context, id int) error
 
-	GetByStatus(ctx context.Context, status PetStatus) ([]*PetDTO, error)
+	// GetByStatus returns page of pets and total number of pets.
+	GetByStatus(ctx context.Context, status PetStatus, params *ListParams) ([]*PetDTO, int, error)
+	// GetByTags find pets by tag names.
+	// With TagMatchAll pet must have every tag, with TagMatchAny at least one.
+	// Returns page of pets and total number of pets.
+	GetByTags(ctx context.Context, tags []string, match TagMatch, params *ListParams) ([]*PetDTO, int, error)
+
+	// UpdateStatusFrom change status of pet only if its current status is from.
+	// Returns false if pet does not exist or has another status.
+	UpdateStatusFrom(ctx context.Context, id int, from PetStatus, to PetStatus) (bool, error)
+	UpdateStatus(ctx context.Context, id int, status PetStatus) error
+
+	CountByStatus(ctx context.Context) (map[PetStatus]int, error)
+	// CountByCategoryAndStatus returns map of category name to number of pets by status.
+	CountByCategoryAndStatus(ctx context.Context) (map[string]map[PetStatus]int, error)
 }
 
 type CategoryRepository interface {
 	Create(ctx context.Context, category *Category) error
 	Get(ctx context.Context, id int) (*Category, error)
+	// GetByIds returns map of category id to category.
+	// Missing categories are not in map.
+	GetByIds(ctx context.Context, ids []int) (map[int]*Category, error)
 	GetByName(ctx context.Context, name string) (*Category, error)
+	// List returns page of categories and total number of categories.
+	List(ctx context.Context, params *ListParams) ([]*Category, int, error)
 
 	// GetElseCreate get else create category.
 	// If category is not exist, create it first.
@@ -85,6 +165,8 @@ type CategoryRepository interface {
 type TagRepository interface {
 	Create(ctx context.Context, tag *Tag) error
 	GetByName(ctx context.Context, name string) (*Tag, error)
+	// List returns page of tags and total number of tags.
+	List(ctx context.Context, params *ListParams) ([]*Tag, int, error)
 
 	// GetElseCreate get else create tag.
 	// If tag is not exist, create it first.
@@ -93,35 +175,75 @@ type TagRepository interface {
 
 	AddTagsToPet(ctx context.Context, petId int, tagIds []int) error
 	GetPetTags(ctx context.Context, petId int) ([]*Tag, error)
+	// GetPetsTags returns map of pet id to pet tags, loaded by one query.
+	GetPetsTags(ctx context.Context, petIds []int) (map[int][]*Tag, error)
 	RemovePetTags(ctx context.Context, petId int) error
 }
 
 type PhotoRepository interface {
-	Create(ctx context.Context, photo PhotoDTO) error
+	Create(ctx context.Context, photo *PhotoDTO) error
+	Delete(ctx context.Context, id int) error
 	GetByPet(ctx context.Context, petId int) ([]*PhotoDTO, error)
+	// GetByPets returns map of pet id to pet photos, loaded by one query.
+	GetByPets(ctx context.Context, petIds []int) (map[int][]*PhotoDTO, error)
+	DeleteByPet(ctx context.Context, petId int) error
 }
 
-func PetDTOToPet(petDTO *PetDTO, category *Category, tags []*Tag) *Pet {
+func PetDTOToPet(petDTO *PetDTO, category *Category, tags []*Tag, photos []*PhotoDTO, thumbnails []ThumbnailSize) *Pet {
+	photoUrls := make([]string, 0, len(photos))
+	petPhotos := make([]*Photo, 0, len(photos))
+	for _, photo := range photos {
+		photoUrls = append(photoUrls, photo.GetPublicURL())
+
+		petPhoto := &Photo{Url: photo.GetPublicURL(), Thumbnails: make(map[string]string, len(thumbnails))}
+		for _, thumbnail := range thumbnails {
+			petPhoto.Thumbnails[thumbnail.Name] = photo.GetThumbnailPublicURL(thumbnail.Name)
+		}
+
+		petPhotos = append(petPhotos, petPhoto)
+	}
+
 	return &Pet{
-		Id:       petDTO.Id,
-		Category: category,
-		Name:     petDTO.Name,
-		Tags:     tags,
-		Status:   petDTO.Status,
+		Id:        petDTO.Id,
+		Category:  category,
+		Name:      petDTO.Name,
+		Tags:      tags,
+		Status:    petDTO.Status,
+		PhotoUrls: photoUrls,
+		Photos:    petPhotos,
 	}
 }
 
 func PetToPetDTO(pet *Pet) *PetDTO {
-	return &PetDTO{
-		Id:         pet.Id,
-		CategoryId: pet.Category.Id,
-		Name:       pet.Name,
-		Status:     pet.Status,
+	petDTO := &PetDTO{
+		Id:     pet.Id,
+		Name:   pet.Name,
+		Status: pet.Status,
 	}
+
+	if pet.Category != nil {
+		petDTO.CategoryId = pet.Category.Id
+	}
+
+	return petDTO
+}
+
+// GetPath returns path of photo file relative to static directory.
+func (p *PhotoDTO) GetPath() string {
+	return fmt.Sprintf("pets/%d/photos/%d.jpg", p.PetId, p.Id)
 }
 
 func (p *PhotoDTO) GetPublicURL() string {
-	return fmt.Sprintf("/static/pets/%d/photos/%d.jpg", p.PetId, p.Id)
+	return "/static/" + p.GetPath()
+}
+
+// GetThumbnailPath returns path of thumbnail file relative to static directory.
+func (p *PhotoDTO) GetThumbnailPath(name string) string {
+	return fmt.Sprintf("pets/%d/photos/%d_%s.jpg", p.PetId, p.Id, name)
+}
+
+func (p *PhotoDTO) GetThumbnailPublicURL(name string) string {
+	return "/static/" + p.GetThumbnailPath(name)
 }
 
 func PetStatusFromString(status string) (PetStatus, error) {
@@ -133,6 +255,17 @@ func PetStatusFromString(status string) (PetStatus, error) {
 	case PetStatusSold:
 		return PetStatusSold, nil
 	default:
-		return PetStatusAvailable, errors.New("invalid status")
+		return PetStatusAvailable, NewValidationError("invalid_pet_status", "invalid status")
+	}
+}
+
+func TagMatchFromString(match string) (TagMatch, error) {
+	switch match {
+	case "", string(TagMatchAny):
+		return TagMatchAny, nil
+	case string(TagMatchAll):
+		return TagMatchAll, nil
+	default:
+		return TagMatchAny, NewValidationError("invalid_tag_match", "invalid tag match, expected any or all")
 	}
 }
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+)
+
+var ErrForbidden = NewForbiddenError("forbidden", "forbidden")
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleStaff    Role = "staff"
+	RoleCustomer Role = "customer"
+)
+
+var Roles = []Role{RoleAdmin, RoleStaff, RoleCustomer}
+
+type Permission string
+
+const (
+	// PermissionPetWrite allows to create, update and delete pets and upload photos
+	PermissionPetWrite Permission = "pet:write"
+	// PermissionOrderReadAll allows to read orders of all users
+	PermissionOrderReadAll Permission = "order:read_all"
+	// PermissionOrderManage allows to change status of orders and delete orders of all users
+	PermissionOrderManage Permission = "order:manage"
+	// PermissionInventoryRead allows to read inventory of store
+	PermissionInventoryRead Permission = "inventory:read"
+	// PermissionUserManage allows to list, update and delete all users and change their roles
+	PermissionUserManage Permission = "user:manage"
+	// PermissionHealthRead allows to read details of health checks of components
+	PermissionHealthRead Permission = "health:read"
+)
+
+// rolePermissions are permissions of each role.
+// Every authenticated user can read pets and place, read and delete own orders.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionPetWrite, PermissionOrderReadAll, PermissionOrderManage,
+		PermissionInventoryRead, PermissionUserManage, PermissionHealthRead,
+	},
+	RoleStaff: {
+		PermissionPetWrite, PermissionOrderReadAll, PermissionOrderManage, PermissionInventoryRead,
+		PermissionHealthRead,
+	},
+	RoleCustomer: {},
+}
+
+func (r Role) Can(permission Permission) bool {
+	for _, rolePermission := range rolePermissions[r] {
+		if rolePermission == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RoleFromString(role string) (Role, error) {
+	switch Role(role) {
+	case RoleAdmin, RoleStaff, RoleCustomer:
+		return Role(role), nil
+	default:
+		return RoleCustomer, NewValidationError("invalid_role", "invalid role")
+	}
+}
+
+// Principal is authenticated user of request.
+type Principal struct {
+	UserId   int
+	Username string
+	Role     Role
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	return p.Role.Can(permission)
+}
+
+// IsUser returns true if principal is user with username.
+func (p *Principal) IsUser(username string) bool {
+	return p.Username == username
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns context with authenticated user.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns authenticated user.
+// Returns false if request is not authenticated.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return principal, ok
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+type TxManager interface {
+	// WithinTx run fn in transaction.
+	// Repositories called with ctx passed to fn run their queries in this transaction.
+	// Transaction is rolled back if fn returns error, else committed.
+	// Nested calls join outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
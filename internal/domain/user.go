@@ -2,13 +2,36 @@ package domain
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
-var ErrUserNotFound = errors.New("user not found")
-var ErrSessionNotFound = errors.New("session not found")
+var ErrUserNotFound = NewNotFoundError("user_not_found", "user not found")
+var ErrSessionNotFound = NewUnauthorizedError("session_not_found", "session not found")
+var ErrWrongPassword = NewUnauthorizedError("wrong_password", "wrong password")
+var ErrUserAlreadyExists = NewConflictError("user_already_exists", "user already exists")
+var ErrInvalidToken = NewUnauthorizedError("invalid_token", "invalid token")
 
+// ErrUserHasOrders is returned on delete of user, who placed orders.
+var ErrUserHasOrders = NewConflictError("user_has_orders", "user has orders")
+
+// ErrInvalidCredentials is returned by login for unknown user and wrong password,
+// so clients can not find out which usernames exist.
+var ErrInvalidCredentials = NewUnauthorizedError("invalid_credentials", "invalid credentials")
+
+// InvalidCredentials returns ErrInvalidCredentials with reason, e.g. ErrWrongPassword.
+// Reason is checked by errors.Is, but it is not shown to clients.
+func InvalidCredentials(reason error) error {
+	return &Error{
+		Kind:    KindUnauthorized,
+		Code:    CodeOf(ErrInvalidCredentials),
+		Message: ErrInvalidCredentials.Error(),
+		Err:     errors.Join(ErrInvalidCredentials, reason),
+	}
+}
+
+// User is stored user, it must not be sent to clients, use UserOutput.
 type User struct {
 	Id         int    `json:"id"`
 	Username   string `json:"username"`
@@ -16,19 +39,78 @@ type User struct {
 	LastName   string `json:"lastName"`
 	Email      string `json:"email"`
 	Phone      string `json:"phone"`
-	Password   string `json:"password"`
+	Password   string `json:"-"`
 	UserStatus int    `json:"userStatus"`
+	Role       Role   `json:"role"`
+}
+
+// UserInput is user sent by client on create and update.
+type UserInput struct {
+	XMLName   xml.Name `json:"-" xml:"User"`
+	Username  string   `json:"username" xml:"username" validate:"required,max=255"`
+	FirstName string   `json:"firstName" xml:"firstName" validate:"max=255"`
+	LastName  string   `json:"lastName" xml:"lastName" validate:"max=255"`
+	Email     string   `json:"email" xml:"email" validate:"max=255,email"`
+	Phone     string   `json:"phone" xml:"phone" validate:"max=255"`
+	// Password is limited by bcrypt, which does not hash passwords longer than 72 bytes
+	Password   string `json:"password" xml:"password" validate:"required,maxbytes=72"`
+	UserStatus int    `json:"userStatus" xml:"userStatus"`
+	// Role is ignored, except when it is set by admin
+	Role Role `json:"role,omitempty" xml:"role,omitempty" validate:"oneof=admin staff customer"`
 }
 
+// UserOutput is user sent to client.
+// Private fields are empty, if client can not see them.
+type UserOutput struct {
+	XMLName   xml.Name `json:"-" xml:"User"`
+	Id        int      `json:"id" xml:"id"`
+	Username  string   `json:"username" xml:"username"`
+	FirstName string   `json:"firstName" xml:"firstName"`
+	LastName  string   `json:"lastName" xml:"lastName"`
+	// Email is visible only to user itself and admin
+	Email string `json:"email,omitempty" xml:"email,omitempty"`
+	// Phone is visible only to user itself and admin
+	Phone string `json:"phone,omitempty" xml:"phone,omitempty"`
+	// UserStatus is visible only to user itself and admin
+	UserStatus int `json:"userStatus,omitempty" xml:"userStatus,omitempty"`
+	// Role is visible only to user itself and admin
+	Role Role `json:"role,omitempty" xml:"role,omitempty"`
+}
+
+// Visibility is set of user fields which client can see.
+type Visibility int
+
+const (
+	// VisibilityPublic - only public fields, for other users and anonymous clients
+	VisibilityPublic Visibility = iota
+	// VisibilitySelf - all fields except password, for user itself
+	VisibilitySelf
+	// VisibilityAdmin - all fields except password, for users with PermissionUserManage
+	VisibilityAdmin
+)
+
 type UserUsecase interface {
-	Create(ctx context.Context, user *User) error
-	Get(ctx context.Context, username string) (*User, error)
-	Update(ctx context.Context, username string, user *User) error
-	Delete(ctx context.Context, username string) error
-	CreateList(ctx context.Context, users []*User) error
+	// Create register new customer.
+	Create(ctx context.Context, user *UserInput) error
+	// Get returns user with fields visible to principal.
+	// Principal is nil for anonymous clients.
+	Get(ctx context.Context, principal *Principal, username string) (*UserOutput, error)
+	// Update update user by principal.
+	// Users can update only themselves and can not change role, except users with PermissionUserManage.
+	Update(ctx context.Context, principal *Principal, username string, user *UserInput) error
+	// Delete delete user by principal.
+	// Users can delete only themselves, except users with PermissionUserManage.
+	Delete(ctx context.Context, principal *Principal, username string) error
+	// CreateList create users with any roles, customer is default role.
+	CreateList(ctx context.Context, users []*UserInput) error
+	// List returns page of users and total number of users.
+	// All fields are visible, because list is only for admin.
+	List(ctx context.Context, params *ListParams) ([]*UserOutput, int, error)
 	Login(ctx context.Context, username string, password string) (string, error)
 	Logout(ctx context.Context, token string) error
-	IsAuthenticated(ctx context.Context, token jwt.Token) (bool, error)
+	// Authenticate returns user of token session.
+	// Return ErrSessionNotFound if session was logout.
+	Authenticate(ctx context.Context, token jwt.Token) (*Principal, error)
 }
 
 type UserRepository interface {
@@ -37,11 +119,61 @@ type UserRepository interface {
 	Update(ctx context.Context, username string, user *User) error
 	Delete(ctx context.Context, username string) error
 	GetIdByUsername(ctx context.Context, username string) (int, error)
+	// List returns page of users and total number of users.
+	List(ctx context.Context, params *ListParams) ([]*User, int, error)
 }
 
 type AuthRepository interface {
 	RegisterSession(ctx context.Context, userId int) (int, error)
 	UnregisterSession(ctx context.Context, sessionId int) error
 	UnregisterAllSession(ctx context.Context, userId int) error
-	ExistsSession(ctx context.Context, sessionId int) (bool, error)
+	// GetPrincipal returns user of session.
+	// Return ErrSessionNotFound if session does not exist.
+	GetPrincipal(ctx context.Context, sessionId int) (*Principal, error)
+}
+
+func UserInputToUser(input *UserInput) *User {
+	return &User{
+		Username:   input.Username,
+		FirstName:  input.FirstName,
+		LastName:   input.LastName,
+		Email:      input.Email,
+		Phone:      input.Phone,
+		Password:   input.Password,
+		UserStatus: input.UserStatus,
+		Role:       input.Role,
+	}
+}
+
+// UserVisibility returns visibility of user fields for principal.
+// Principal is nil for anonymous clients.
+func UserVisibility(principal *Principal, user *User) Visibility {
+	switch {
+	case principal == nil:
+		return VisibilityPublic
+	case principal.Can(PermissionUserManage):
+		return VisibilityAdmin
+	case principal.UserId == user.Id:
+		return VisibilitySelf
+	default:
+		return VisibilityPublic
+	}
+}
+
+func UserToUserOutput(user *User, visibility Visibility) *UserOutput {
+	output := &UserOutput{
+		Id:        user.Id,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+
+	if visibility == VisibilitySelf || visibility == VisibilityAdmin {
+		output.Email = user.Email
+		output.Phone = user.Phone
+		output.UserStatus = user.UserStatus
+		output.Role = user.Role
+	}
+
+	return output
 }
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// encoding/xml does not support maps, so types with maps encode them as list of elements
+// with name in attribute, e.g. <thumbnail name="small">/static/pets/1/photos/1_small.jpg</thumbnail>.
+
+type xmlEntry struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// sortedKeys returns keys of map in order, so output is stable.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func xmlEntries[V any](m map[string]V) []xmlEntry {
+	entries := make([]xmlEntry, 0, len(m))
+	for _, name := range sortedKeys(m) {
+		entries = append(entries, xmlEntry{Name: name, Value: fmt.Sprint(m[name])})
+	}
+
+	return entries
+}
+
+func xmlStatusEntries(m map[PetStatus]int) []xmlEntry {
+	statuses := make(map[string]int, len(m))
+	for status, count := range m {
+		statuses[string(status)] = count
+	}
+
+	return xmlEntries(statuses)
+}
+
+type xmlPhoto struct {
+	Url        string     `xml:"url"`
+	Thumbnails []xmlEntry `xml:"thumbnails>thumbnail"`
+}
+
+func (p *Photo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(xmlPhoto{Url: p.Url, Thumbnails: xmlEntries(p.Thumbnails)}, start)
+}
+
+// UnmarshalXML decode photo, so pet sent back by client is accepted.
+func (p *Photo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var photo xmlPhoto
+	if err := d.DecodeElement(&photo, &start); err != nil {
+		return err
+	}
+
+	p.Url = photo.Url
+	p.Thumbnails = make(map[string]string, len(photo.Thumbnails))
+	for _, thumbnail := range photo.Thumbnails {
+		p.Thumbnails[thumbnail.Name] = thumbnail.Value
+	}
+
+	return nil
+}
+
+type xmlCategoryInventory struct {
+	Name     string     `xml:"name,attr"`
+	Statuses []xmlEntry `xml:"status"`
+}
+
+func (i *Inventory) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	inventory := struct {
+		Statuses   []xmlEntry             `xml:"statuses>status"`
+		Categories []xmlCategoryInventory `xml:"categories>category,omitempty"`
+	}{Statuses: xmlStatusEntries(i.Statuses)}
+
+	for _, category := range sortedKeys(i.Categories) {
+		inventory.Categories = append(inventory.Categories, xmlCategoryInventory{
+			Name:     category,
+			Statuses: xmlStatusEntries(i.Categories[category]),
+		})
+	}
+
+	return e.EncodeElement(inventory, start)
+}
+
+type xmlComponentHealth struct {
+	Name     string       `xml:"name,attr"`
+	Status   HealthStatus `xml:"status"`
+	Critical bool         `xml:"critical"`
+	Latency  string       `xml:"latency"`
+	Error    string       `xml:"error,omitempty"`
+	Details  []xmlEntry   `xml:"details>detail,omitempty"`
+}
+
+func (h *Health) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	health := struct {
+		Status     HealthStatus         `xml:"status"`
+		Components []xmlComponentHealth `xml:"components>component,omitempty"`
+	}{Status: h.Status}
+
+	for _, component := range sortedKeys(h.Components) {
+		componentHealth := h.Components[component]
+		health.Components = append(health.Components, xmlComponentHealth{
+			Name:     component,
+			Status:   componentHealth.Status,
+			Critical: componentHealth.Critical,
+			Latency:  componentHealth.Latency,
+			Error:    componentHealth.Error,
+			Details:  xmlEntries(componentHealth.Details),
+		})
+	}
+
+	return e.EncodeElement(health, start)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"github.com/go-chi/chi"
+	"net/http"
+	"petstore/internal/domain"
+	"petstore/internal/health"
+	"petstore/internal/responder"
+)
+
+type healthController struct {
+	responder     responder.Responder
+	healthUsecase domain.HealthUsecase
+	readiness     *health.Readiness
+}
+
+// NewHealthController register routes of health checks.
+// optionalAuthenticator is used for /healthz, which shows components only to users with PermissionHealthRead.
+func NewHealthController(r chi.Router, resp responder.Responder, hu domain.HealthUsecase,
+	readiness *health.Readiness, optionalAuthenticator func(http.Handler) http.Handler) {
+	controller := &healthController{responder: resp, healthUsecase: hu, readiness: readiness}
+
+	r.Get("/livez", controller.Live)
+	r.With(optionalAuthenticator).Get("/healthz", controller.Health)
+	r.Get("/readyz", controller.Ready)
+}
+
+// Live this function is used by orchestrator to check that process is not stuck.
+// It does not check dependencies, so their failure does not restart application.
+//
+// @Summary		Liveness of application
+// @Tags		health
+// @Produce		json,application/xml
+// @Success		200		{string}	string				"Alive"
+// @Router		/livez	[get]
+func (h *healthController) Live(w http.ResponseWriter, r *http.Request) {
+	h.responder.Output(w, responder.Response{
+		Success: true,
+		Message: "alive",
+		Data:    nil,
+	})
+}
+
+// Health this function is used to check state of application and its dependencies.
+//
+// @Summary		Health of application
+// @Description	Checks database, migrations and blob storage. Returns 503 if any critical component is down.
+// @Description	Components are returned only to staff and admin, others get only status.
+// @Tags		health
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+// @Success		200		{object}	domain.Health		"Up or degraded"
+// @Failure		503		{object}	domain.Health		"Down"
+// @Router		/healthz	[get]
+func (h *healthController) Health(w http.ResponseWriter, r *http.Request) {
+	report := h.healthUsecase.Check(r.Context())
+
+	// principal is not set for anonymous clients
+	if principal, ok := domain.PrincipalFromContext(r.Context()); !ok || !principal.Can(domain.PermissionHealthRead) {
+		report = report.Summary()
+	}
+
+	h.outputHealth(w, report)
+}
+
+// Ready this function is used by load balancer to check that application accepts requests.
+//
+// @Summary		Readiness of application
+// @Description	Like /healthz, but also returns 503 when application drains requests before shutdown.
+// @Description	Only status is returned.
+// @Tags		health
+// @Produce		json,application/xml
+// @Success		200		{object}	domain.Health		"Ready"
+// @Failure		503		{object}	domain.Health		"Not ready"
+// @Router		/readyz	[get]
+func (h *healthController) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.readiness.IsDraining() {
+		h.outputHealth(w, &domain.Health{Status: domain.HealthStatusDraining})
+		return
+	}
+
+	h.outputHealth(w, h.healthUsecase.Check(r.Context()).Summary())
+}
+
+// outputHealth - write report with 503 status code, if application can not serve requests.
+func (h *healthController) outputHealth(w http.ResponseWriter, report *domain.Health) {
+	statusCode := http.StatusOK
+	if report.Status == domain.HealthStatusDown || report.Status == domain.HealthStatusDraining {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	h.responder.OutputWithStatus(w, statusCode, responder.Response{
+		Success: statusCode == http.StatusOK,
+		Message: string(report.Status),
+		Data:    report,
+	})
+}
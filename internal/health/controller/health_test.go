@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/ptflp/godecoder"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"petstore/internal/domain"
+	"petstore/internal/health"
+	"petstore/internal/responder"
+	"strings"
+	"testing"
+)
+
+type stubHealthUsecase struct{}
+
+func (stubHealthUsecase) Check(context.Context) *domain.Health {
+	return &domain.Health{
+		Status: domain.HealthStatusDown,
+		Components: map[string]*domain.ComponentHealth{
+			"db": {Status: domain.HealthStatusDown, Critical: true, Error: "dial tcp 10.0.0.1:5432: connection refused",
+				Details: map[string]interface{}{"openConnections": 0}},
+		},
+	}
+}
+
+// newTestRouter register health routes, role of user is taken from header.
+func newTestRouter() http.Handler {
+	resp := responder.NewResponder(godecoder.NewDecoder(), zap.NewNop())
+	optionalAuthenticator := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if role := r.Header.Get("X-Role"); role != "" {
+				principal := &domain.Principal{UserId: 1, Role: domain.Role(role)}
+				r = r.WithContext(domain.ContextWithPrincipal(r.Context(), principal))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	r := chi.NewRouter()
+	NewHealthController(r, resp, stubHealthUsecase{}, health.NewReadiness(), optionalAuthenticator)
+
+	return r
+}
+
+func TestHealthDetailsAreNotPublic(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		path    string
+		role    domain.Role
+		details bool
+	}{
+		{path: "/healthz", role: "", details: false},
+		{path: "/healthz", role: domain.RoleCustomer, details: false},
+		{path: "/healthz", role: domain.RoleStaff, details: true},
+		{path: "/readyz", role: domain.RoleAdmin, details: false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-Role", string(tt.role))
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s as %q: got status %d, want 503", tt.path, tt.role, rec.Code)
+		}
+
+		var body struct {
+			Data domain.Health `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s as %q: decode: %v", tt.path, tt.role, err)
+		}
+
+		if body.Data.Status != domain.HealthStatusDown {
+			t.Errorf("%s as %q: got status %q, want down", tt.path, tt.role, body.Data.Status)
+		}
+
+		if hasDetails := strings.Contains(rec.Body.String(), "connection refused"); hasDetails != tt.details {
+			t.Errorf("%s as %q: details in response %v, want %v", tt.path, tt.role, hasDetails, tt.details)
+		}
+
+		if hasComponents := len(body.Data.Components) > 0; hasComponents != tt.details {
+			t.Errorf("%s as %q: components in response %v, want %v", tt.path, tt.role, hasComponents, tt.details)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package health
+
+import "sync/atomic"
+
+// Readiness is state of application for load balancer.
+// Application is not ready, when it drains requests before shutdown.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Drain mark application as not ready, it can not be undone.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *Readiness) IsDraining() bool {
+	return r.draining.Load()
+}
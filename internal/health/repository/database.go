@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"petstore/internal/domain"
+)
+
+type databaseChecker struct {
+	Conn *sql.DB
+}
+
+// NewDatabaseChecker create checker of database connectivity, details are stats of connection pool.
+func NewDatabaseChecker(conn *sql.DB) domain.HealthChecker {
+	return &databaseChecker{Conn: conn}
+}
+
+func (d *databaseChecker) Check(ctx context.Context) (map[string]interface{}, error) {
+	stats := d.Conn.Stats()
+	details := map[string]interface{}{
+		"openConnections": stats.OpenConnections,
+		"inUse":           stats.InUse,
+		"idle":            stats.Idle,
+		"maxOpen":         stats.MaxOpenConnections,
+	}
+
+	return details, d.Conn.PingContext(ctx)
+}
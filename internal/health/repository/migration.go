@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"petstore/internal/domain"
+	"petstore/internal/migration"
+)
+
+type migrationChecker struct {
+	Migrator *migration.Migrator
+}
+
+// NewMigrationChecker create checker of database schema, it fails if any migration is not applied.
+func NewMigrationChecker(migrator *migration.Migrator) domain.HealthChecker {
+	return &migrationChecker{Migrator: migrator}
+}
+
+func (m *migrationChecker) Check(ctx context.Context) (map[string]interface{}, error) {
+	version, err := m.Migrator.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := m.Migrator.Latest()
+	details := map[string]interface{}{"version": version, "latest": latest}
+	if version < latest {
+		return details, fmt.Errorf("schema is not migrated, version %d, latest %d", version, latest)
+	}
+
+	return details, nil
+}
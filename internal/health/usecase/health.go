@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"sync"
+	"time"
+)
+
+// Component is checked dependency of application.
+type Component struct {
+	Name    string
+	Checker domain.HealthChecker
+	// Critical is true, if application can not serve requests without component
+	Critical bool
+}
+
+type healthUsecase struct {
+	components []Component
+	timeout    time.Duration
+}
+
+func NewHealthUsecase(components []Component, timeout time.Duration) domain.HealthUsecase {
+	return &healthUsecase{components: components, timeout: timeout}
+}
+
+func (h *healthUsecase) Check(ctx context.Context) *domain.Health {
+	results := make([]*domain.ComponentHealth, len(h.components))
+
+	var wg sync.WaitGroup
+	for i, component := range h.components {
+		wg.Add(1)
+		go func(i int, component Component) {
+			defer wg.Done()
+			results[i] = h.check(ctx, component)
+		}(i, component)
+	}
+	wg.Wait()
+
+	health := &domain.Health{
+		Status:     domain.HealthStatusUp,
+		Components: make(map[string]*domain.ComponentHealth, len(h.components)),
+	}
+	for i, component := range h.components {
+		result := results[i]
+		health.Components[component.Name] = result
+
+		if result.Status == domain.HealthStatusDown {
+			if component.Critical {
+				health.Status = domain.HealthStatusDown
+			} else if health.Status == domain.HealthStatusUp {
+				health.Status = domain.HealthStatusDegraded
+			}
+		}
+	}
+
+	return health
+}
+
+// check - run check of component with timeout.
+// Error is logged, because it is not in public report.
+func (h *healthUsecase) check(ctx context.Context, component Component) *domain.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := component.Checker.Check(ctx)
+
+	result := &domain.ComponentHealth{
+		Status:   domain.HealthStatusUp,
+		Critical: component.Critical,
+		Latency:  time.Since(start).String(),
+		Details:  details,
+	}
+
+	if err != nil {
+		result.Status = domain.HealthStatusDown
+		result.Error = err.Error()
+
+		logging.FromContext(ctx).Warn("health check failed", zap.String("component", component.Name),
+			zap.Bool("critical", component.Critical), zap.Error(err))
+	}
+
+	return result
+}
@@ -0,0 +1,150 @@
+package listing
+
+import (
+	"cmp"
+	"fmt"
+	"petstore/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field is field of item, which is listed in memory.
+// Value returns int, string, time.Time or bool by Kind, enums are returned as strings.
+type Field[T any] struct {
+	Kind  ColumnKind
+	Value func(item T) interface{}
+	// Values are allowed values of KindEnum, like Values of Column
+	Values []string
+}
+
+// Fields maps public name of field to field, like Columns for queries.
+type Fields[T any] map[string]Field[T]
+
+// List apply list params to items in memory, it has the same errors as Filter and Paginate.
+// Items must be ordered by id, so order of pages is stable.
+// Returns page of items and total number of filtered items.
+func List[T any](items []T, params *domain.ListParams, fields Fields[T]) ([]T, int, error) {
+	for _, filter := range params.Filters {
+		field, ok := fields[filter.Field]
+		if !ok {
+			return nil, 0, domain.FieldError("filter",
+				fmt.Errorf("%w: filter by %s is not supported", domain.ErrInvalidListParams, filter.Field))
+		}
+
+		values := make([]interface{}, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			parsed, err := parseValue(field.Kind, field.Values, value)
+			if err != nil {
+				return nil, 0, domain.FieldError("filter",
+					fmt.Errorf("%w: invalid value of %s", domain.ErrInvalidListParams, filter.Field))
+			}
+
+			values = append(values, parsed)
+		}
+
+		match, err := matcher(field.Kind, filter.Operator, values)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		filtered := make([]T, 0, len(items))
+		for _, item := range items {
+			if match(field.Value(item)) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	for _, sortField := range params.Sort {
+		if _, ok := fields[sortField.Field]; !ok {
+			return nil, 0, domain.FieldError("sort",
+				fmt.Errorf("%w: sort by %s is not supported", domain.ErrInvalidListParams, sortField.Field))
+		}
+	}
+
+	sorted := append(make([]T, 0, len(items)), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, sortField := range params.Sort {
+			value := fields[sortField.Field].Value
+			if c := compare(value(sorted[i]), value(sorted[j])); c != 0 {
+				return (c < 0) != sortField.Desc
+			}
+		}
+
+		return false
+	})
+
+	total := len(sorted)
+	start := min(params.Offset(), total)
+	end := min(start+params.Limit, total)
+
+	return sorted[start:end], total, nil
+}
+
+// matcher returns function which checks value of field, like condition for queries.
+func matcher(kind ColumnKind, operator domain.FilterOperator, values []interface{}) (func(value interface{}) bool, error) {
+	switch operator {
+	case domain.FilterEq:
+		return func(value interface{}) bool { return compare(value, values[0]) == 0 }, nil
+	case domain.FilterNe:
+		return func(value interface{}) bool { return compare(value, values[0]) != 0 }, nil
+	case domain.FilterLt:
+		return func(value interface{}) bool { return compare(value, values[0]) < 0 }, nil
+	case domain.FilterLte:
+		return func(value interface{}) bool { return compare(value, values[0]) <= 0 }, nil
+	case domain.FilterGt:
+		return func(value interface{}) bool { return compare(value, values[0]) > 0 }, nil
+	case domain.FilterGte:
+		return func(value interface{}) bool { return compare(value, values[0]) >= 0 }, nil
+	case domain.FilterIn:
+		return func(value interface{}) bool {
+			for _, v := range values {
+				if compare(value, v) == 0 {
+					return true
+				}
+			}
+
+			return false
+		}, nil
+	case domain.FilterLike:
+		if kind != KindString {
+			return nil, domain.FieldError("filter",
+				fmt.Errorf("%w: like is supported only for strings", domain.ErrInvalidListParams))
+		}
+
+		// like of queries is case insensitive and matches substring
+		pattern := strings.ToLower(values[0].(string))
+		return func(value interface{}) bool {
+			return strings.Contains(strings.ToLower(value.(string)), pattern)
+		}, nil
+	default:
+		return nil, domain.FieldError("filter",
+			fmt.Errorf("%w: unknown filter operator %s", domain.ErrInvalidListParams, operator))
+	}
+}
+
+// compare returns -1, 0 or 1, values must have the same type, false is less than true.
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case string:
+		return cmp.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case bool:
+		b := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		default:
+			return 1
+		}
+	default:
+		panic(fmt.Sprintf("listing: unsupported type of field %T", a))
+	}
+}
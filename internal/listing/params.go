@@ -0,0 +1,82 @@
+package listing
+
+import (
+	"fmt"
+	"net/http"
+	"petstore/internal/domain"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+	// MaxPage limits offset of page, so it does not overflow
+	MaxPage = 1_000_000
+)
+
+// ParseParams parse list params from query of request.
+//
+// Supported query params:
+//   - page, limit: page starts from 1, limit is page size
+//   - sort: comma separated fields, "-" prefix for descending order, e.g. "sort=-name,id"
+//   - filter: "field:operator:value", can be repeated, e.g. "filter=name:like:cat".
+//     Operators: eq, ne, lt, lte, gt, gte, like, in. Values of "in" are separated by "|".
+func ParseParams(r *http.Request) (*domain.ListParams, error) {
+	query := r.URL.Query()
+	params := &domain.ListParams{Page: 1, Limit: DefaultLimit}
+
+	if page := query.Get("page"); page != "" {
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 || pageInt > MaxPage {
+			return nil, domain.FieldError("page",
+				fmt.Errorf("%w: page must be integer from 1 to %d", domain.ErrInvalidListParams, MaxPage))
+		}
+
+		params.Page = pageInt
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > MaxLimit {
+			return nil, domain.FieldError("limit",
+				fmt.Errorf("%w: limit must be integer from 1 to %d", domain.ErrInvalidListParams, MaxLimit))
+		}
+
+		params.Limit = limitInt
+	}
+
+	for _, sort := range query["sort"] {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			desc := strings.HasPrefix(field, "-")
+			params.Sort = append(params.Sort, domain.SortField{Field: strings.TrimPrefix(field, "-"), Desc: desc})
+		}
+	}
+
+	for _, filter := range query["filter"] {
+		field, rest, found := strings.Cut(filter, ":")
+		operator, value, foundValue := strings.Cut(rest, ":")
+		if !found || !foundValue || field == "" {
+			return nil, domain.FieldError("filter",
+				fmt.Errorf("%w: filter must be in format field:operator:value", domain.ErrInvalidListParams))
+		}
+
+		values := []string{value}
+		if domain.FilterOperator(operator) == domain.FilterIn {
+			values = strings.Split(value, "|")
+		}
+
+		params.Filters = append(params.Filters, domain.Filter{
+			Field:    field,
+			Operator: domain.FilterOperator(operator),
+			Values:   values,
+		})
+	}
+
+	return params, nil
+}
@@ -0,0 +1,200 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"petstore/internal/domain"
+	"petstore/internal/tracing"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ColumnKind int
+
+const (
+	KindString ColumnKind = iota
+	KindInt
+	KindTime
+	KindBool
+	// KindEnum is string with limited set of values, it can not be filtered by like
+	KindEnum
+)
+
+// likeEscaper escape special symbols of like pattern, so value is matched as is
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type Column struct {
+	// Name is SQL name of column
+	Name string
+	Kind ColumnKind
+	// Values are allowed values of KindEnum, postgres fails on other values of enum type
+	Values []string
+}
+
+// EnumValues convert values of enum type to Values of Column and Field.
+func EnumValues[T ~string](values []T) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+
+	return result
+}
+
+// Columns maps public name of field to column.
+// Only fields in Columns can be used for sorting and filtering.
+type Columns map[string]Column
+
+// Filter add filters of params to query.
+func Filter(query sq.SelectBuilder, params *domain.ListParams, columns Columns) (sq.SelectBuilder, error) {
+	for _, filter := range params.Filters {
+		column, ok := columns[filter.Field]
+		if !ok {
+			return query, domain.FieldError("filter",
+				fmt.Errorf("%w: filter by %s is not supported", domain.ErrInvalidListParams, filter.Field))
+		}
+
+		values := make([]interface{}, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			parsed, err := parseValue(column.Kind, column.Values, value)
+			if err != nil {
+				return query, domain.FieldError("filter",
+					fmt.Errorf("%w: invalid value of %s", domain.ErrInvalidListParams, filter.Field))
+			}
+
+			values = append(values, parsed)
+		}
+
+		condition, err := condition(column, filter.Operator, values)
+		if err != nil {
+			return query, err
+		}
+
+		query = query.Where(condition)
+	}
+
+	return query, nil
+}
+
+// Paginate add order and limit of params to query.
+// Rows are always ordered by idColumn in the end, so pages are stable.
+func Paginate(query sq.SelectBuilder, params *domain.ListParams, columns Columns, idColumn string) (sq.SelectBuilder, error) {
+	for _, sort := range params.Sort {
+		column, ok := columns[sort.Field]
+		if !ok {
+			return query, domain.FieldError("sort",
+				fmt.Errorf("%w: sort by %s is not supported", domain.ErrInvalidListParams, sort.Field))
+		}
+
+		if sort.Desc {
+			query = query.OrderBy(column.Name + " DESC")
+		} else {
+			query = query.OrderBy(column.Name + " ASC")
+		}
+	}
+
+	query = query.OrderBy(idColumn + " ASC")
+
+	return query.Limit(uint64(params.Limit)).Offset(uint64(params.Offset())), nil
+}
+
+// Query apply params to query, count filtered rows and load rows of page.
+// scan is called for each row of page.
+// Returns page of items and total number of filtered rows.
+func Query[T any](ctx context.Context, conn tracing.Runner, query sq.SelectBuilder, params *domain.ListParams,
+	columns Columns, idColumn string, scan func(row sq.RowScanner) (T, error)) ([]T, int, error) {
+	query, err := Filter(query, params, columns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := Count(ctx, conn, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query, err = Paginate(query, params, columns, idColumn)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := conn.QueryRows(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, total, rows.Err()
+}
+
+// Count returns number of rows in query without limit.
+func Count(ctx context.Context, conn sq.BaseRunner, query sq.SelectBuilder) (int, error) {
+	countQuery := sq.Select("COUNT(*)").FromSelect(query, "list").PlaceholderFormat(sq.Dollar)
+
+	var count int
+	err := countQuery.RunWith(conn).QueryRowContext(ctx).Scan(&count)
+
+	return count, err
+}
+
+func condition(column Column, operator domain.FilterOperator, values []interface{}) (sq.Sqlizer, error) {
+	switch operator {
+	case domain.FilterEq:
+		return sq.Eq{column.Name: values[0]}, nil
+	case domain.FilterNe:
+		return sq.NotEq{column.Name: values[0]}, nil
+	case domain.FilterLt:
+		return sq.Lt{column.Name: values[0]}, nil
+	case domain.FilterLte:
+		return sq.LtOrEq{column.Name: values[0]}, nil
+	case domain.FilterGt:
+		return sq.Gt{column.Name: values[0]}, nil
+	case domain.FilterGte:
+		return sq.GtOrEq{column.Name: values[0]}, nil
+	case domain.FilterIn:
+		return sq.Eq{column.Name: values}, nil
+	case domain.FilterLike:
+		if column.Kind != KindString {
+			return nil, domain.FieldError("filter",
+				fmt.Errorf("%w: like is supported only for strings", domain.ErrInvalidListParams))
+		}
+
+		return sq.ILike{column.Name: "%" + likeEscaper.Replace(values[0].(string)) + "%"}, nil
+	default:
+		return nil, domain.FieldError("filter",
+			fmt.Errorf("%w: unknown filter operator %s", domain.ErrInvalidListParams, operator))
+	}
+}
+
+// parseValue - parse value of filter by kind, value of enum must be one of allowed values.
+func parseValue(kind ColumnKind, allowed []string, value string) (interface{}, error) {
+	switch kind {
+	case KindEnum:
+		if !slices.Contains(allowed, value) {
+			return nil, fmt.Errorf("%s is not one of %s", value, strings.Join(allowed, ", "))
+		}
+
+		return value, nil
+	case KindInt:
+		return strconv.Atoi(value)
+	case KindTime:
+		return time.Parse(time.RFC3339, value)
+	case KindBool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
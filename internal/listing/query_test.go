@@ -0,0 +1,107 @@
+package listing
+
+import (
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"net/http/httptest"
+	"petstore/internal/domain"
+	"testing"
+)
+
+var statuses = []string{"available", "pending", "sold"}
+
+type pet struct {
+	Id     int
+	Status string
+}
+
+var petColumns = Columns{
+	"id":     {Name: "id", Kind: KindInt},
+	"status": {Name: "status", Kind: KindEnum, Values: statuses},
+}
+
+var petFields = Fields[pet]{
+	"id":     {Kind: KindInt, Value: func(p pet) interface{} { return p.Id }},
+	"status": {Kind: KindEnum, Value: func(p pet) interface{} { return p.Status }, Values: statuses},
+}
+
+// assertFilterError check that err is invalid list params of filter field.
+func assertFilterError(t *testing.T, err error) {
+	t.Helper()
+
+	if !errors.Is(err, domain.ErrInvalidListParams) {
+		t.Fatalf("got %v, want %v", err, domain.ErrInvalidListParams)
+	}
+
+	if violations := domain.ViolationsOf(err); len(violations) != 1 || violations[0].Field != "filter" {
+		t.Errorf("got violations %+v, want filter", violations)
+	}
+}
+
+func TestFilterEnumValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter domain.Filter
+		valid  bool
+	}{
+		{name: "eq", filter: domain.Filter{Field: "status", Operator: domain.FilterEq, Values: []string{"sold"}}, valid: true},
+		{name: "in", filter: domain.Filter{Field: "status", Operator: domain.FilterIn, Values: []string{"sold", "pending"}},
+			valid: true},
+		{name: "unknown eq", filter: domain.Filter{Field: "status", Operator: domain.FilterEq, Values: []string{"foo"}}},
+		{name: "unknown in", filter: domain.Filter{Field: "status", Operator: domain.FilterIn, Values: []string{"sold", "foo"}}},
+	}
+
+	items := []pet{{Id: 1, Status: "available"}, {Id: 2, Status: "sold"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &domain.ListParams{Page: 1, Limit: DefaultLimit, Filters: []domain.Filter{tt.filter}}
+
+			_, err := Filter(sq.Select("id").From("pets"), params, petColumns)
+			_, _, memoryErr := List(items, params, petFields)
+
+			if tt.valid {
+				if err != nil || memoryErr != nil {
+					t.Fatalf("got %v and %v in memory, want no errors", err, memoryErr)
+				}
+
+				return
+			}
+
+			// both storages reject value, which is not in enum type of postgres
+			assertFilterError(t, err)
+			assertFilterError(t, memoryErr)
+		})
+	}
+}
+
+func TestParseParamsPage(t *testing.T) {
+	tests := []struct {
+		query string
+		valid bool
+	}{
+		{query: "page=1", valid: true},
+		{query: "page=1000000&limit=100", valid: true},
+		{query: "page=0"},
+		{query: "page=1000001"},
+		{query: "page=9223372036854775807&limit=100"},
+	}
+
+	for _, tt := range tests {
+		params, err := ParseParams(httptest.NewRequest("GET", "/pets?"+tt.query, nil))
+		if !tt.valid {
+			if !errors.Is(err, domain.ErrInvalidListParams) {
+				t.Errorf("%s: got %v, want %v", tt.query, err, domain.ErrInvalidListParams)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+
+		if params.Offset() < 0 {
+			t.Errorf("%s: got offset %d", tt.query, params.Offset())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// requestEntry is data of request, which is known only after routing and authentication.
+type requestEntry struct {
+	logger   *zap.Logger
+	routeCtx *chi.Context
+	userId   int
+	username string
+}
+
+// fields returns route and user of request, if they are known.
+func (e *requestEntry) fields() []zap.Field {
+	fields := make([]zap.Field, 0, 3)
+	if e.routeCtx != nil && e.routeCtx.RoutePattern() != "" {
+		fields = append(fields, zap.String("route", e.routeCtx.RoutePattern()))
+	}
+
+	if e.username != "" {
+		fields = append(fields, zap.Int("userId", e.userId), zap.String("user", e.username))
+	}
+
+	return fields
+}
+
+type entryKey struct{}
+
+// responseWriter gives access to request entry for code which has only writer, e.g. responder.
+type responseWriter struct {
+	middleware.WrapResponseWriter
+	entry *requestEntry
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.WrapResponseWriter
+}
+
+// Middleware set logger with request id and trace id to context and log each completed request.
+// Requests to quietPaths, e.g. health probes, are logged on debug level, unless they fail with 5xx.
+// It must be used on root router after RequestID.
+func Middleware(logger *zap.Logger, quietPaths ...string) func(http.Handler) http.Handler {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			fields := []zap.Field{zap.String("requestId", RequestIDFromContext(r.Context()))}
+			if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.IsValid() {
+				fields = append(fields, zap.String("traceId", spanCtx.TraceID().String()))
+			}
+
+			entry := &requestEntry{
+				logger:   logger.With(fields...),
+				routeCtx: chi.RouteContext(r.Context()),
+			}
+
+			ctx := WithLogger(r.Context(), entry.logger)
+			ctx = context.WithValue(ctx, entryKey{}, entry)
+			ww := &responseWriter{WrapResponseWriter: middleware.NewWrapResponseWriter(w, r.ProtoMajor), entry: entry}
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := zap.InfoLevel
+			if quiet[r.URL.Path] && status < http.StatusInternalServerError {
+				level = zap.DebugLevel
+			}
+
+			entry.logger.Log(level, "request completed", append(entry.fields(),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", status),
+				zap.Int("bytes", ww.BytesWritten()),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("remoteAddr", r.RemoteAddr),
+			)...)
+		}
+
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// WithUser add authenticated user to logs of request.
+// Returns context with logger, which has user fields.
+func WithUser(ctx context.Context, userId int, username string) context.Context {
+	if entry, ok := ctx.Value(entryKey{}).(*requestEntry); ok {
+		entry.userId = userId
+		entry.username = username
+	}
+
+	return WithLogger(ctx, FromContext(ctx).With(zap.Int("userId", userId), zap.String("user", username)))
+}
+
+// FromWriter returns logger of request with its request id, route and user.
+// Returns nil if writer is not created by Middleware.
+func FromWriter(w http.ResponseWriter) *zap.Logger {
+	for {
+		switch writer := w.(type) {
+		case *responseWriter:
+			return writer.entry.logger.With(writer.entry.fields()...)
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
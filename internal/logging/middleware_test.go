@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareQuietPaths(t *testing.T) {
+	tests := []struct {
+		path   string
+		status int
+		level  zapcore.Level
+	}{
+		{path: "/pet/1", status: http.StatusOK, level: zapcore.InfoLevel},
+		{path: "/readyz", status: http.StatusOK, level: zapcore.DebugLevel},
+		{path: "/readyz", status: http.StatusServiceUnavailable, level: zapcore.InfoLevel},
+	}
+
+	for _, tt := range tests {
+		core, logs := observer.New(zapcore.DebugLevel)
+		handler := RequestID(Middleware(zap.New(core), "/readyz")(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		entries := logs.FilterMessage("request completed").All()
+		if len(entries) != 1 {
+			t.Fatalf("%s: got %d log entries, want 1", tt.path, len(entries))
+		}
+
+		if entries[0].Level != tt.level {
+			t.Errorf("%s with %d: got level %s, want %s", tt.path, tt.status, entries[0].Level, tt.level)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"petstore/internal/domain"
+	"sync"
+)
+
+// Session is row of auth table.
+type Session struct {
+	Id     int
+	UserId int
+}
+
+// PetTag is row of pets_tags table.
+type PetTag struct {
+	PetId int
+	TagId int
+}
+
+// Tables are tables of database schema, which are kept in memory.
+type Tables struct {
+	Users      *Table[domain.User]
+	Sessions   *Table[Session]
+	Categories *Table[domain.Category]
+	Tags       *Table[domain.Tag]
+	Pets       *Table[domain.PetDTO]
+	PetTags    *Table[PetTag]
+	Photos     *Table[domain.PhotoDTO]
+	Orders     *Table[domain.Order]
+}
+
+func (t *Tables) clone() *Tables {
+	return &Tables{
+		Users:      t.Users.clone(),
+		Sessions:   t.Sessions.clone(),
+		Categories: t.Categories.clone(),
+		Tags:       t.Tags.clone(),
+		Pets:       t.Pets.clone(),
+		PetTags:    t.PetTags.clone(),
+		Photos:     t.Photos.clone(),
+		Orders:     t.Orders.clone(),
+	}
+}
+
+// DB is thread-safe database in memory, which replaces postgres in tests and demos.
+// Data is lost when process stops.
+//
+// Writes are serialized: transaction holds write lock until it ends, so it sees no concurrent changes,
+// and writes outside transaction wait for it. Reads outside transaction can see uncommitted changes.
+type DB struct {
+	// mu guards tables
+	mu sync.RWMutex
+	// writeMu is held by transaction or by single write outside transaction
+	writeMu sync.Mutex
+	tables  *Tables
+}
+
+func NewDB() *DB {
+	return &DB{tables: &Tables{
+		Users:      newTable[domain.User](),
+		Sessions:   newTable[Session](),
+		Categories: newTable[domain.Category](),
+		Tags:       newTable[domain.Tag](),
+		Pets:       newTable[domain.PetDTO](),
+		PetTags:    newTable[PetTag](),
+		Photos:     newTable[domain.PhotoDTO](),
+		Orders:     newTable[domain.Order](),
+	}}
+}
+
+// Read run fn with tables, fn must not change them.
+func (d *DB) Read(ctx context.Context, fn func(tables *Tables) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return fn(d.tables)
+}
+
+// Write run fn with tables, which it can change.
+// Outside transaction fn must check everything before first change, because changes are not rolled back.
+func (d *DB) Write(ctx context.Context, fn func(tables *Tables) error) error {
+	if !d.inTx(ctx) {
+		d.writeMu.Lock()
+		defer d.writeMu.Unlock()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return fn(d.tables)
+}
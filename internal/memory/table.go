@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"maps"
+	"sort"
+)
+
+// Table is rows of one type by id, ids are assigned from sequence like ids of serial column.
+// Rows are stored by value, so callers can not change them without Put.
+type Table[T any] struct {
+	rows map[int]T
+	// seq is shared by clones, so rollback restores rows, but not sequence, as in postgres
+	seq *int
+}
+
+func newTable[T any]() *Table[T] {
+	return &Table[T]{rows: make(map[int]T), seq: new(int)}
+}
+
+// NextId returns next id of sequence, ids are not reused after delete or rollback.
+func (t *Table[T]) NextId() int {
+	*t.seq++
+	return *t.seq
+}
+
+func (t *Table[T]) Get(id int) (T, bool) {
+	row, ok := t.rows[id]
+	return row, ok
+}
+
+func (t *Table[T]) Put(id int, row T) {
+	t.rows[id] = row
+}
+
+// Delete returns false if row does not exist.
+func (t *Table[T]) Delete(id int) bool {
+	if _, ok := t.rows[id]; !ok {
+		return false
+	}
+
+	delete(t.rows, id)
+	return true
+}
+
+// Rows returns rows ordered by id.
+func (t *Table[T]) Rows() []T {
+	ids := make([]int, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	rows := make([]T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, t.rows[id])
+	}
+
+	return rows
+}
+
+// Find returns first row by id which matches fn.
+func (t *Table[T]) Find(fn func(row T) bool) (T, bool) {
+	for _, row := range t.Rows() {
+		if fn(row) {
+			return row, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// DeleteWhere delete rows which match fn.
+func (t *Table[T]) DeleteWhere(fn func(row T) bool) {
+	for id, row := range t.rows {
+		if fn(row) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+func (t *Table[T]) clone() *Table[T] {
+	return &Table[T]{rows: maps.Clone(t.rows), seq: t.seq}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"petstore/internal/domain"
+)
+
+type txKey struct{}
+
+type txManager struct {
+	DB *DB
+}
+
+// NewTxManager create manager of transactions of db.
+// Transaction restores rows of tables from snapshot, which is taken on its start, if fn returns error or panics.
+// Sequences of ids are not restored, as in postgres.
+func NewTxManager(db *DB) domain.TxManager {
+	return &txManager{DB: db}
+}
+
+func (t *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.DB.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	t.DB.writeMu.Lock()
+
+	t.DB.mu.RLock()
+	snapshot := t.DB.tables.clone()
+	t.DB.mu.RUnlock()
+
+	committed := false
+	defer func() {
+		if !committed {
+			t.DB.mu.Lock()
+			t.DB.tables = snapshot
+			t.DB.mu.Unlock()
+		}
+
+		t.DB.writeMu.Unlock()
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, t.DB)); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// inTx returns true if ctx is context of transaction of db.
+func (d *DB) inTx(ctx context.Context) bool {
+	db, ok := ctx.Value(txKey{}).(*DB)
+	return ok && db == d
+}
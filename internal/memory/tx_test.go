@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"petstore/internal/domain"
+	"testing"
+)
+
+func TestRollbackDoesNotReuseIds(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	txManager := NewTxManager(db)
+
+	var rolledBack int
+	errFailed := errors.New("failed")
+	err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return db.Write(ctx, func(tables *Tables) error {
+			rolledBack = tables.Tags.NextId()
+			tables.Tags.Put(rolledBack, domain.Tag{Id: rolledBack, Name: "friendly"})
+
+			return errFailed
+		})
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("got %v, want %v", err, errFailed)
+	}
+
+	err = db.Write(ctx, func(tables *Tables) error {
+		if _, ok := tables.Tags.Get(rolledBack); ok {
+			t.Error("row is not rolled back")
+		}
+
+		if id := tables.Tags.NextId(); id == rolledBack {
+			t.Errorf("id %d of rolled back row is reused", id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute is label of requests which do not match any route,
+// raw path is not used to keep number of series bounded.
+const unmatchedRoute = "unmatched"
+
+// Middleware count requests and measure their latency by chi route pattern, e.g. /pet/{petId}.
+// It must be used on root router, so pattern contains all sub routers.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	hfn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+
+	return http.HandlerFunc(hfn)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"petstore/internal/domain"
+)
+
+// petUsecase count business events of pets, other methods are passed to wrapped usecase.
+type petUsecase struct {
+	domain.PetUsecase
+	metrics *Metrics
+}
+
+func (m *Metrics) WrapPetUsecase(pu domain.PetUsecase) domain.PetUsecase {
+	return &petUsecase{PetUsecase: pu, metrics: m}
+}
+
+func (p *petUsecase) Create(ctx context.Context, pet *domain.Pet) error {
+	err := p.PetUsecase.Create(ctx, pet)
+	if err == nil {
+		p.metrics.petsCreated.Inc()
+	}
+
+	return err
+}
+
+// orderUsecase count orders by status, other methods are passed to wrapped usecase.
+type orderUsecase struct {
+	domain.OrderUsecase
+	metrics *Metrics
+}
+
+func (m *Metrics) WrapOrderUsecase(ou domain.OrderUsecase) domain.OrderUsecase {
+	return &orderUsecase{OrderUsecase: ou, metrics: m}
+}
+
+func (o *orderUsecase) Create(ctx context.Context, order *domain.Order) error {
+	err := o.OrderUsecase.Create(ctx, order)
+	if err == nil {
+		o.metrics.orders.WithLabelValues(string(order.Status)).Inc()
+	}
+
+	return err
+}
+
+func (o *orderUsecase) UpdateStatus(ctx context.Context, id int, status domain.OrderStatus,
+	principal *domain.Principal) (*domain.Order, error) {
+	order, err := o.OrderUsecase.UpdateStatus(ctx, id, status, principal)
+	if err == nil {
+		o.metrics.orders.WithLabelValues(string(order.Status)).Inc()
+	}
+
+	return order, err
+}
+
+// userUsecase count failed logins, other methods are passed to wrapped usecase.
+type userUsecase struct {
+	domain.UserUsecase
+	metrics *Metrics
+}
+
+func (m *Metrics) WrapUserUsecase(uu domain.UserUsecase) domain.UserUsecase {
+	return &userUsecase{UserUsecase: uu, metrics: m}
+}
+
+func (u *userUsecase) Login(ctx context.Context, username string, password string) (string, error) {
+	token, err := u.UserUsecase.Login(ctx, username, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			u.metrics.loginFailures.WithLabelValues("user_not_found").Inc()
+		case errors.Is(err, domain.ErrWrongPassword):
+			u.metrics.loginFailures.WithLabelValues("wrong_password").Inc()
+		default:
+			u.metrics.loginFailures.WithLabelValues("error").Inc()
+		}
+	}
+
+	return token, err
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"petstore/internal/config"
+	"petstore/internal/migration"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+// RunMigrate run command of migrations without starting server:
+// up applies pending migrations, down reverts last steps migrations, status prints state of migrations.
+// If dryRun is true, up and down print SQL instead of executing it.
+func RunMigrate(cfg *config.Config, command string, steps int, dryRun bool) error {
+	if cfg.Storage != config.StoragePostgres {
+		return fmt.Errorf("migrations require %s storage, storage is %s", config.StoragePostgres, cfg.Storage)
+	}
+
+	logger := initLogger(cfg.Log)
+	defer logger.Sync()
+
+	db := initDB(cfg.DB, logger)
+	defer db.Close()
+
+	migrator := initMigrator(db, logger)
+	ctx := context.Background()
+
+	switch command {
+	case MigrateUp:
+		return migrator.Up(ctx, dryRun, os.Stdout)
+	case MigrateDown:
+		return migrator.Down(ctx, steps, dryRun, os.Stdout)
+	case MigrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected %s, %s or %s", command, MigrateUp, MigrateDown, MigrateStatus)
+	}
+}
+
+func initMigrator(db *sql.DB, logger *zap.Logger) *migration.Migrator {
+	migrator, err := migration.NewMigrator(db, logger)
+	if err != nil {
+		logger.Panic("failed to load migrations", zap.Error(err))
+	}
+
+	return migrator
+}
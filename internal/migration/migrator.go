@@ -0,0 +1,276 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"go.uber.org/zap"
+	"io"
+	"time"
+)
+
+// lockKey is key of postgres advisory lock, which is held while migrations are applied,
+// so replicas started at the same time don't apply the same migration.
+const lockKey int64 = 7_384_231_905
+
+const table = "schema_migrations"
+
+// queryer is *sql.DB or *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Status is state of migration in database.
+type Status struct {
+	Version int64
+	Name    string
+	// AppliedAt is nil if migration is pending
+	AppliedAt *time.Time
+}
+
+// Migrator apply migrations to database and records applied versions in schema_migrations table.
+type Migrator struct {
+	Conn       *sql.DB
+	SqlBuilder sq.StatementBuilderType
+	Migrations []*Migration
+	Logger     *zap.Logger
+}
+
+// NewMigrator create migrator of embedded migrations.
+func NewMigrator(conn *sql.DB, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		Conn:       conn,
+		SqlBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		Migrations: migrations,
+		Logger:     logger,
+	}, nil
+}
+
+// Latest returns version of last migration, 0 if there are no migrations.
+func (m *Migrator) Latest() int64 {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Up apply pending migrations in order of versions, each migration is applied in own transaction.
+// If dryRun is true, SQL of pending migrations is written to out and database is not changed.
+func (m *Migrator) Up(ctx context.Context, dryRun bool, out io.Writer) error {
+	return m.withLock(ctx, dryRun, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if dryRun {
+				if err := writeSQL(out, migration, "up", migration.Up); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			m.Logger.Info("applying migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+
+			insert := m.SqlBuilder.Insert(table).Columns("version", "name").Values(migration.Version, migration.Name)
+			if err := m.run(ctx, conn, migration.Up, insert); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down revert last steps applied migrations in reverse order of versions.
+// If dryRun is true, SQL of migrations is written to out and database is not changed.
+func (m *Migrator) Down(ctx context.Context, steps int, dryRun bool, out io.Writer) error {
+	return m.withLock(ctx, dryRun, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.Migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			steps--
+
+			if dryRun {
+				if err := writeSQL(out, migration, "down", migration.Down); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			m.Logger.Info("reverting migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+
+			del := m.SqlBuilder.Delete(table).Where(sq.Eq{"version": migration.Version})
+			if err := m.run(ctx, conn, migration.Down, del); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns state of each migration ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.Conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Version returns last applied version, 0 if no migrations are applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	exists, err := m.tableExists(ctx, m.Conn)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	err = m.Conn.QueryRowContext(ctx, "SELECT max(version) FROM "+table).Scan(&version)
+
+	return version.Int64, err
+}
+
+// withLock run fn on single connection, which holds advisory lock, and creates schema_migrations table.
+// Dry run doesn't lock and doesn't create table.
+func (m *Migrator) withLock(ctx context.Context, dryRun bool, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.Conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if dryRun {
+		return fn(conn)
+	}
+
+	m.Logger.Debug("waiting for migration lock")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey); unlockErr != nil {
+			// connection is closed instead of returning to pool, so postgres releases lock of session
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			err = errors.Join(err, fmt.Errorf("failed to release migration lock: %w", unlockErr))
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", table, err)
+	}
+
+	return fn(conn)
+}
+
+// run execute SQL of migration and change of schema_migrations in one transaction.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, query string, record sq.Sqlizer) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	recordQuery, args, err := record.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, recordQuery, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applied returns time of applying of each applied version.
+func (m *Migrator) applied(ctx context.Context, conn queryer) (map[int64]time.Time, error) {
+	applied := make(map[int64]time.Time)
+
+	exists, err := m.tableExists(ctx, conn)
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM "+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, conn queryer) (bool, error) {
+	var exists bool
+	err := conn.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists)
+
+	return exists, err
+}
+
+func writeSQL(out io.Writer, migration *Migration, direction string, query string) error {
+	_, err := fmt.Fprintf(out, "-- %04d_%s %s\n%s\n", migration.Version, migration.Name, direction, query)
+	return err
+}
@@ -0,0 +1,198 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDSNEnv is name of environment variable with dsn of postgres for tests, tests of migrator are skipped without it.
+const testDSNEnv = "PETSTORE_TEST_POSTGRES_DSN"
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("got %d migrations, want versions 1 and 2", len(migrations))
+	}
+
+	// 0001 is schema of init.sql, later changes are in own migrations
+	if strings.Contains(migrations[0].Up, "role") || strings.Contains(migrations[0].Up, "cancelled") {
+		t.Error("0001 contains changes, which are not in init.sql")
+	}
+}
+
+// openTestDB open postgres of testDSNEnv with new schema, which is dropped after test.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	// lib/pq sends unknown parameters to postgres, so each connection uses schema of test
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "search_path=" + schema
+	} else {
+		dsn += " search_path=" + schema
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newTestMigrator(t *testing.T, db *sql.DB) *Migrator {
+	t.Helper()
+
+	migrator, err := NewMigrator(db, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return migrator
+}
+
+func TestUpgradeInitSQLDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	// database created by removed init.sql with an order, which has no owner
+	initSQL, err := os.ReadFile("testdata/init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setup := []string{
+		string(initSQL),
+		"INSERT INTO users (username, password, user_status) VALUES ('bob', 'hash', 0)",
+		"INSERT INTO categories (name) VALUES ('dogs')",
+		"INSERT INTO pets (name, status, category_id) VALUES ('rex', 'available', 1)",
+		"INSERT INTO orders (pet_id, ship_date, status) VALUES (1, now(), 'placed')",
+	}
+	for _, query := range setup {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrator := newTestMigrator(t, db)
+	if err := migrator.Up(ctx, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if version, err := migrator.Version(ctx); err != nil || version != migrator.Latest() {
+		t.Fatalf("got version %d, %v, want %d", version, err, migrator.Latest())
+	}
+
+	var role string
+	if err := db.QueryRowContext(ctx, "SELECT role FROM users WHERE username = 'bob'").Scan(&role); err != nil {
+		t.Fatal(err)
+	}
+
+	if role != "customer" {
+		t.Errorf("got role %s of existing user, want customer", role)
+	}
+
+	var owner string
+	err = db.QueryRowContext(ctx, "SELECT u.username FROM orders o JOIN users u ON u.id = o.user_id").Scan(&owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owner != "legacy_orders" {
+		t.Errorf("got owner %s of existing order, want legacy_orders", owner)
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO orders (user_id, pet_id, status) VALUES (1, 1, 'cancelled')")
+	if err != nil {
+		t.Errorf("cancelled order is not inserted: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO orders (pet_id, status) VALUES (1, 'placed')"); err == nil {
+		t.Error("order without owner is inserted")
+	}
+
+	// revert restores database of init.sql without owner of orders
+	if _, err := db.ExecContext(ctx, "DELETE FROM orders WHERE status = 'cancelled'"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Down(ctx, 1, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	var users int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	if users != 1 {
+		t.Errorf("got %d users after revert of 0002, want only bob", users)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := newTestMigrator(t, db)
+
+	if err := migrator.Up(ctx, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Down(ctx, 1, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' " +
+		"AND column_name = 'role' AND table_schema = current_schema())"
+	if err := db.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Error("role column exists after revert of 0002")
+	}
+
+	if err := migrator.Down(ctx, 1, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	// reverted migrations are applied again
+	if err := migrator.Up(ctx, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS orders;
+DROP TYPE IF EXISTS OrderStatus;
+
+DROP TABLE IF EXISTS photos;
+DROP TABLE IF EXISTS pets_tags;
+DROP TABLE IF EXISTS pets;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TYPE IF EXISTS PetStatus;
+
+DROP TABLE IF EXISTS auth;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,63 @@
+-- Schema of removed init.sql. Databases created by init.sql already have it, so statements skip existing objects.
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) UNIQUE NOT NULL,
+    first_name VARCHAR(255),
+    last_name VARCHAR(255),
+    email VARCHAR(255),
+    phone VARCHAR(255),
+    password VARCHAR(255),
+    user_status int
+);
+
+CREATE TABLE IF NOT EXISTS auth (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users (id)
+);
+
+DO $$ BEGIN
+    CREATE TYPE PetStatus AS ENUM ('available', 'pending','sold');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pets (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255),
+    status  PetStatus,
+
+    category_id INTEGER REFERENCES categories (id)
+);
+
+CREATE TABLE IF NOT EXISTS pets_tags (
+    pet_id INTEGER REFERENCES pets (id),
+    tag_id INTEGER REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS photos (
+    id SERIAL PRIMARY KEY,
+    pet_id INTEGER REFERENCES pets (id)
+);
+
+DO $$ BEGIN
+    CREATE TYPE OrderStatus AS ENUM ('placed', 'approved','delivered');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    pet_id INTEGER REFERENCES pets (id),
+    ship_date TIMESTAMP,
+    status OrderStatus,
+    complete BOOLEAN DEFAULT false
+);
@@ -0,0 +1,19 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS user_id;
+-- owner of orders without owner is created by up
+DELETE FROM users WHERE username = 'legacy_orders';
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS UserRole;
+
+-- values of enum can not be dropped, so type is recreated,
+-- orders with new statuses must be removed first, because previous schema can not store them
+DO $$ BEGIN
+    IF EXISTS (SELECT 1 FROM orders WHERE status IN ('cancelled', 'returned')) THEN
+        RAISE EXCEPTION 'orders with cancelled or returned status must be removed before revert';
+    END IF;
+END $$;
+
+ALTER TYPE OrderStatus RENAME TO OrderStatus_old;
+CREATE TYPE OrderStatus AS ENUM ('placed', 'approved','delivered');
+ALTER TABLE orders ALTER COLUMN status TYPE OrderStatus USING status::text::OrderStatus;
+DROP TYPE OrderStatus_old;
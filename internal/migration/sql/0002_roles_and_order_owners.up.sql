@@ -0,0 +1,26 @@
+-- Roles of users, owners of orders and statuses of cancelled and returned orders.
+-- Statements skip existing objects, so databases created by init.sql of the same version are upgraded too.
+
+ALTER TYPE OrderStatus ADD VALUE IF NOT EXISTS 'cancelled';
+ALTER TYPE OrderStatus ADD VALUE IF NOT EXISTS 'returned';
+
+DO $$ BEGIN
+    CREATE TYPE UserRole AS ENUM ('admin', 'staff', 'customer');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+-- existing users become customers, first admin is set manually
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role UserRole NOT NULL DEFAULT 'customer';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id);
+
+-- owner of existing orders is unknown, they are given to user without password,
+-- who can not log in, so only staff see them
+INSERT INTO users (username, first_name, last_name, email, phone, password, user_status)
+SELECT 'legacy_orders', '', '', '', '', '', 0
+WHERE EXISTS (SELECT 1 FROM orders WHERE user_id IS NULL)
+ON CONFLICT (username) DO NOTHING;
+
+UPDATE orders SET user_id = (SELECT id FROM users WHERE username = 'legacy_orders') WHERE user_id IS NULL;
+
+ALTER TABLE orders ALTER COLUMN user_id SET NOT NULL;
@@ -1,12 +1,14 @@
 package controller
 
 import (
-	"encoding/json"
 	"errors"
 	"github.com/go-chi/chi"
 	"net/http"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
 	"petstore/internal/responder"
+	"petstore/internal/user/controller/middleware"
+	"petstore/internal/validation"
 	"strconv"
 )
 
@@ -19,28 +21,38 @@ type orderController struct {
 //
 // @Summary		Add a new order to the store
 // @Tags 		store
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		order		body	domain.Order		true	"Order object that needs to be added to the store"
 //
 // @Success		200		{object}	domain.Order		"Order object that was added"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		404		{string}	string				"Pet not found"
+// @Failure		409		{string}	string				"Pet is not available"
 // @Router		/store/order 	[post]
 func (o *orderController) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
 	var orderInput domain.Order
-	if err := json.NewDecoder(r.Body).Decode(&orderInput); err != nil {
+	if err := validation.Decode(w, r, &orderInput); err != nil {
 		o.responder.ErrorBadRequest(w, err)
 		return
 	}
 
+	orderInput.UserId = principal.UserId
+
 	if err := o.orderUsecase.Create(r.Context(), &orderInput); err != nil {
-		o.responder.ErrorInternal(w, err)
+		o.responder.Error(w, err)
 		return
 	}
 
-	o.responder.OutputJSON(w, responder.Response{
+	o.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "order created",
 		Data:    orderInput,
@@ -50,36 +62,44 @@ func (o *orderController) Create(w http.ResponseWriter, r *http.Request) {
 // Get this function is used to get an order from the store.
 //
 // @Summary		Order an order by ID
+// @Description	Users can get only own orders, staff can get all orders.
 // @Tags 		store
-// @Produce		json
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		orderId	path		int					true	"ID of order to return"
 //
 // @Success		200		{object}	domain.Order		"Find order by ID"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"Order not found"
 // @Router		/store/order/{orderId} 		[get]
 func (o *orderController) Get(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
 	orderIdParam := chi.URLParam(r, "orderId")
 	if orderIdParam == "" {
-		o.responder.ErrorBadRequest(w, errors.New("orderId is required"))
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId is required")))
 		return
 	}
 
 	orderId, err := strconv.Atoi(orderIdParam)
 	if err != nil {
-		o.responder.ErrorBadRequest(w, err)
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId must be integer")))
 		return
 	}
 
-	order, err := o.orderUsecase.Get(r.Context(), orderId)
+	order, err := o.orderUsecase.Get(r.Context(), orderId, principal)
 	if err != nil {
-		o.responder.ErrorInternal(w, err)
+		o.responder.Error(w, err)
 		return
 	}
 
-	o.responder.OutputJSON(w, responder.Response{
+	o.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "get order",
 		Data:    order,
@@ -89,48 +109,247 @@ func (o *orderController) Get(w http.ResponseWriter, r *http.Request) {
 // Delete this function is used to delete an order from the store.
 //
 // @Summary		Delete order by ID
+// @Description	Users can delete only own orders, staff can delete all orders.
 // @Tags 		store
-// @Produce		json
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		orderId	path		int					true	"ID of order to delete"
 //
 // @Success		200		{string}	string				"Order deleted"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"Order not found"
 // @Router		/store/order/{orderId} 	[delete]
 func (o *orderController) Delete(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
 	orderIdParam := chi.URLParam(r, "orderId")
 	if orderIdParam == "" {
-		o.responder.ErrorBadRequest(w, errors.New("orderId is required"))
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId is required")))
 		return
 	}
 
 	orderId, err := strconv.Atoi(orderIdParam)
 	if err != nil {
-		o.responder.ErrorBadRequest(w, err)
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId must be integer")))
 		return
 	}
 
-	err = o.orderUsecase.Delete(r.Context(), orderId)
+	err = o.orderUsecase.Delete(r.Context(), orderId, principal)
 	if err != nil {
-		o.responder.ErrorInternal(w, err)
+		o.responder.Error(w, err)
 		return
 	}
 
-	o.responder.OutputJSON(w, responder.Response{
+	o.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "order deleted",
 		Data:    nil,
 	})
 }
 
+type UpdateStatusRequest struct {
+	Status domain.OrderStatus `json:"status" xml:"status" validate:"required,oneof=placed approved delivered cancelled returned"`
+}
+
+// UpdateStatus this function is used to move an order to new status.
+//
+// @Summary		Update status of order
+// @Description	Allowed transitions: placed -> approved, cancelled; approved -> delivered, cancelled; delivered -> returned.
+// @Description	Users can only cancel own orders, staff can move all orders.
+// @Tags 		store
+// @Accept		json,application/xml
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		orderId	path		int						true	"ID of order to update"
+// @Param		status	body		UpdateStatusRequest		true	"New status of order"
+//
+// @Success		200		{object}	domain.Order		"Updated order"
+// @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
+// @Failure		404		{string}	string				"Order not found"
+// @Failure		409		{string}	string				"Transition is not allowed"
+// @Router		/store/order/{orderId}/status 	[put]
+func (o *orderController) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
+	orderIdParam := chi.URLParam(r, "orderId")
+	if orderIdParam == "" {
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId is required")))
+		return
+	}
+
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		o.responder.ErrorBadRequest(w, domain.FieldError("orderId", errors.New("param orderId must be integer")))
+		return
+	}
+
+	var statusInput UpdateStatusRequest
+	if err := validation.Decode(w, r, &statusInput); err != nil {
+		o.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	order, err := o.orderUsecase.UpdateStatus(r.Context(), orderId, statusInput.Status, principal)
+	if err != nil {
+		o.responder.Error(w, err)
+		return
+	}
+
+	o.responder.Output(w, responder.Response{
+		Success: true,
+		Message: "order status updated",
+		Data:    order,
+	})
+}
+
+// List this function is used to get orders of the store.
+//
+// @Summary		List orders
+// @Description	Staff get orders of all users, other users get only own orders.
+// @Tags 		store
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.Order		"Page of orders"
+// @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
+// @Router		/store/order 	[get]
+func (o *orderController) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		o.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	orders, total, err := o.orderUsecase.List(r.Context(), params, principal)
+	if err != nil {
+		o.responder.Error(w, err)
+		return
+	}
+
+	o.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "list orders",
+		Data:       orders,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
+// Inventory this function is used to get number of pets by status.
+//
+// @Summary		Returns pet inventories by status
+// @Tags 		store
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		byCategory	query	bool				false	"Add breakdown by category"	default(false)
+//
+// @Success		200		{object}	domain.Inventory	"Number of pets by status"
+// @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
+// @Router		/store/inventory 	[get]
+func (o *orderController) Inventory(w http.ResponseWriter, r *http.Request) {
+	byCategory := false
+	if param := r.URL.Query().Get("byCategory"); param != "" {
+		var err error
+		byCategory, err = strconv.ParseBool(param)
+		if err != nil {
+			o.responder.ErrorBadRequest(w, domain.FieldError("byCategory", errors.New("param byCategory must be boolean")))
+			return
+		}
+	}
+
+	inventory, err := o.orderUsecase.GetInventory(r.Context(), byCategory)
+	if err != nil {
+		o.responder.Error(w, err)
+		return
+	}
+
+	o.responder.Output(w, responder.Response{
+		Success: true,
+		Message: "get inventory",
+		Data:    inventory,
+	})
+}
+
+// ListMine this function is used to get orders of authenticated user.
+//
+// @Summary		List my orders
+// @Tags 		store
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.Order		"Page of orders"
+// @Failure		400		{string}	string				"Invalid input"
+// @Router		/store/orders 	[get]
+func (o *orderController) ListMine(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		o.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		o.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	orders, total, err := o.orderUsecase.ListByUser(r.Context(), principal.UserId, params)
+	if err != nil {
+		o.responder.Error(w, err)
+		return
+	}
+
+	o.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "list my orders",
+		Data:       orders,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
 func NewOrderController(r chi.Router, responder responder.Responder, orderUsecase domain.OrderUsecase) {
 	controller := &orderController{orderUsecase: orderUsecase, responder: responder}
 
+	r.With(middleware.RequirePermission(responder, domain.PermissionInventoryRead)).
+		Get("/store/inventory", controller.Inventory)
+	r.Get("/store/orders", controller.ListMine)
+
 	r.Route("/store/order", func(r chi.Router) {
 		r.Get("/{orderId}", controller.Get)
 		r.Delete("/{orderId}", controller.Delete)
 		r.Post("/", controller.Create)
+
+		r.Put("/{orderId}/status", controller.UpdateStatus)
+		r.Get("/", controller.List)
 	})
 }
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/ptflp/godecoder"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"petstore/internal/domain"
+	"petstore/internal/responder"
+	"strconv"
+	"testing"
+)
+
+// stubOrderUsecase returns order 1 of user 1, other orders do not exist.
+type stubOrderUsecase struct {
+	domain.OrderUsecase
+}
+
+func (stubOrderUsecase) Get(_ context.Context, id int, principal *domain.Principal) (*domain.Order, error) {
+	if id != 1 {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	if principal.UserId != 1 && !principal.Can(domain.PermissionOrderReadAll) {
+		return nil, domain.ErrForbidden
+	}
+
+	return &domain.Order{Id: 1, UserId: 1}, nil
+}
+
+func (stubOrderUsecase) List(context.Context, *domain.ListParams, *domain.Principal) ([]*domain.Order, int, error) {
+	return []*domain.Order{}, 0, nil
+}
+
+// newTestRouter register routes of orders behind authenticator, which takes role from header.
+// Request without header is rejected.
+func newTestRouter(orderUsecase domain.OrderUsecase) http.Handler {
+	resp := responder.NewResponder(godecoder.NewDecoder(), zap.NewNop())
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := r.Header.Get("X-Role")
+			if role == "" {
+				resp.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+				return
+			}
+
+			userId, _ := strconv.Atoi(r.Header.Get("X-User-Id"))
+			principal := &domain.Principal{UserId: userId, Role: domain.Role(role)}
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(r.Context(), principal)))
+		})
+	})
+	NewOrderController(r, resp, orderUsecase)
+
+	return r
+}
+
+func TestListRequiresAuthentication(t *testing.T) {
+	router := newTestRouter(stubOrderUsecase{})
+
+	tests := []struct {
+		role   domain.Role
+		status int
+	}{
+		{role: "", status: http.StatusUnauthorized},
+		{role: domain.RoleCustomer, status: http.StatusOK},
+		{role: domain.RoleAdmin, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/store/order/", nil)
+		req.Header.Set("X-Role", string(tt.role))
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("role %q: got status %d, want %d", tt.role, rec.Code, tt.status)
+		}
+	}
+}
+
+func TestGetErrorStatus(t *testing.T) {
+	router := newTestRouter(stubOrderUsecase{})
+
+	tests := []struct {
+		name    string
+		orderId string
+		userId  string
+		status  int
+	}{
+		{name: "owner", orderId: "1", userId: "1", status: http.StatusOK},
+		{name: "another user", orderId: "1", userId: "2", status: http.StatusForbidden},
+		{name: "missing order", orderId: "2", userId: "1", status: http.StatusNotFound},
+		{name: "invalid id", orderId: "one", userId: "1", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/store/order/"+tt.orderId, nil)
+		req.Header.Set("X-Role", string(domain.RoleCustomer))
+		req.Header.Set("X-User-Id", tt.userId)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/memory"
+	"slices"
+)
+
+// orderFields are fields of order available for sorting and filtering in memory, like orderColumns
+var orderFields = listing.Fields[domain.Order]{
+	"id":       {Kind: listing.KindInt, Value: func(o domain.Order) interface{} { return o.Id }},
+	"userId":   {Kind: listing.KindInt, Value: func(o domain.Order) interface{} { return o.UserId }},
+	"petId":    {Kind: listing.KindInt, Value: func(o domain.Order) interface{} { return o.PetId }},
+	"shipDate": {Kind: listing.KindTime, Value: func(o domain.Order) interface{} { return o.ShipDate }},
+	"status":   {Kind: listing.KindEnum, Value: func(o domain.Order) interface{} { return string(o.Status) }, Values: orderStatuses},
+	"complete": {Kind: listing.KindBool, Value: func(o domain.Order) interface{} { return o.Complete }},
+}
+
+type memoryOrderRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryOrderRepository(db *memory.DB) domain.OrderRepository {
+	return &memoryOrderRepository{DB: db}
+}
+
+func (o *memoryOrderRepository) Get(ctx context.Context, id int) (*domain.Order, error) {
+	var order domain.Order
+	err := o.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if order, ok = tables.Orders.Get(id); !ok {
+			return domain.ErrOrderNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetForUpdate is the same as Get, transactions in memory are serialized.
+func (o *memoryOrderRepository) GetForUpdate(ctx context.Context, id int) (*domain.Order, error) {
+	return o.Get(ctx, id)
+}
+
+func (o *memoryOrderRepository) Create(ctx context.Context, order *domain.Order) error {
+	return o.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Pets.Get(order.PetId); !ok {
+			return domain.ErrPetNotFound
+		}
+
+		if _, ok := tables.Users.Get(order.UserId); !ok {
+			return domain.ErrUserNotFound
+		}
+
+		order.Id = tables.Orders.NextId()
+		tables.Orders.Put(order.Id, *order)
+
+		return nil
+	})
+}
+
+func (o *memoryOrderRepository) UpdateStatus(ctx context.Context, id int, status domain.OrderStatus,
+	complete bool) error {
+	return o.DB.Write(ctx, func(tables *memory.Tables) error {
+		order, ok := tables.Orders.Get(id)
+		if !ok {
+			return domain.ErrOrderNotFound
+		}
+
+		order.Status = status
+		order.Complete = complete
+		tables.Orders.Put(id, order)
+
+		return nil
+	})
+}
+
+func (o *memoryOrderRepository) Delete(ctx context.Context, id int) error {
+	return o.DB.Write(ctx, func(tables *memory.Tables) error {
+		if !tables.Orders.Delete(id) {
+			return domain.ErrOrderNotFound
+		}
+
+		return nil
+	})
+}
+
+func (o *memoryOrderRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Order, int, error) {
+	return o.list(ctx, params, func(order domain.Order) bool {
+		return true
+	})
+}
+
+func (o *memoryOrderRepository) ListByUser(ctx context.Context, userId int,
+	params *domain.ListParams) ([]*domain.Order, int, error) {
+	return o.list(ctx, params, func(order domain.Order) bool {
+		return order.UserId == userId
+	})
+}
+
+func (o *memoryOrderRepository) HasActiveByPet(ctx context.Context, petId int) (bool, error) {
+	var exists bool
+	err := o.DB.Read(ctx, func(tables *memory.Tables) error {
+		_, exists = tables.Orders.Find(func(order domain.Order) bool {
+			return order.PetId == petId && slices.Contains(domain.ActiveOrderStatuses, order.Status)
+		})
+
+		return nil
+	})
+
+	return exists, err
+}
+
+// list - apply list params to orders which match fn.
+func (o *memoryOrderRepository) list(ctx context.Context, params *domain.ListParams,
+	fn func(order domain.Order) bool) ([]*domain.Order, int, error) {
+	var page []domain.Order
+	var total int
+	err := o.DB.Read(ctx, func(tables *memory.Tables) error {
+		orders := make([]domain.Order, 0)
+		for _, order := range tables.Orders.Rows() {
+			if fn(order) {
+				orders = append(orders, order)
+			}
+		}
+
+		var err error
+		page, total, err = listing.List(orders, params, orderFields)
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*domain.Order, 0, len(page))
+	for i := range page {
+		items = append(items, &page[i])
+	}
+
+	return items, total, nil
+}
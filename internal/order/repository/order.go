@@ -6,6 +6,8 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/transaction"
 )
 
 type orderRepository struct {
@@ -16,7 +18,7 @@ type orderRepository struct {
 func (o *orderRepository) Delete(ctx context.Context, id int) error {
 	query := o.SqlBuilder.Delete("orders").Where(sq.Eq{"id": id})
 
-	res, err := query.RunWith(o.Conn).ExecContext(ctx)
+	res, err := query.RunWith(transaction.Conn(ctx, o.Conn)).ExecContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -33,12 +35,23 @@ func (o *orderRepository) Delete(ctx context.Context, id int) error {
 }
 
 func (o *orderRepository) Get(ctx context.Context, id int) (*domain.Order, error) {
-	query := o.SqlBuilder.Select("id", "pet_id", "ship_date", "status", "complete").From("orders")
+	query := o.SqlBuilder.Select("id", "user_id", "pet_id", "ship_date", "status", "complete").From("orders")
 	query = query.Where(sq.Eq{"id": id})
 
-	row := query.RunWith(o.Conn).QueryRowContext(ctx)
+	return o.get(ctx, query)
+}
+
+func (o *orderRepository) GetForUpdate(ctx context.Context, id int) (*domain.Order, error) {
+	query := o.SqlBuilder.Select("id", "user_id", "pet_id", "ship_date", "status", "complete").From("orders")
+	query = query.Where(sq.Eq{"id": id}).Suffix("FOR UPDATE")
+
+	return o.get(ctx, query)
+}
+
+func (o *orderRepository) get(ctx context.Context, query sq.SelectBuilder) (*domain.Order, error) {
+	row := query.RunWith(transaction.Conn(ctx, o.Conn)).QueryRowContext(ctx)
 	var order domain.Order
-	if err := row.Scan(&order.Id, &order.PetId, &order.ShipDate, &order.Status, &order.Complete); err != nil {
+	if err := row.Scan(&order.Id, &order.UserId, &order.PetId, &order.ShipDate, &order.Status, &order.Complete); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrOrderNotFound
 		}
@@ -49,15 +62,92 @@ func (o *orderRepository) Get(ctx context.Context, id int) (*domain.Order, error
 	return &order, nil
 }
 
+func (o *orderRepository) UpdateStatus(ctx context.Context, id int, status domain.OrderStatus, complete bool) error {
+	query := o.SqlBuilder.Update("orders").Set("status", status).Set("complete", complete)
+	query = query.Where(sq.Eq{"id": id})
+
+	res, err := query.RunWith(transaction.Conn(ctx, o.Conn)).ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err != nil {
+			return err
+		} else {
+			return domain.ErrOrderNotFound
+		}
+	}
+
+	return nil
+}
+
 func (o *orderRepository) Create(ctx context.Context, order *domain.Order) error {
-	query := o.SqlBuilder.Insert("orders").Columns("pet_id", "ship_date", "status", "complete")
-	query = query.Values(order.PetId, order.ShipDate, order.Status, order.Complete)
+	query := o.SqlBuilder.Insert("orders").Columns("user_id", "pet_id", "ship_date", "status", "complete")
+	query = query.Values(order.UserId, order.PetId, order.ShipDate, order.Status, order.Complete)
 	query = query.Suffix("RETURNING id")
 
-	row := query.RunWith(o.Conn).QueryRowContext(ctx)
-	return row.Scan(&order.Id)
+	row := query.RunWith(transaction.Conn(ctx, o.Conn)).QueryRowContext(ctx)
+	err := row.Scan(&order.Id)
+	switch {
+	case transaction.IsForeignKeyViolation(err, "orders_pet_id_fkey"):
+		return domain.ErrPetNotFound
+	case transaction.IsForeignKeyViolation(err, "orders_user_id_fkey"):
+		return domain.ErrUserNotFound
+	default:
+		return err
+	}
+}
+
+func (o *orderRepository) HasActiveByPet(ctx context.Context, petId int) (bool, error) {
+	query := o.SqlBuilder.Select("1").From("orders")
+	query = query.Where(sq.Eq{"pet_id": petId, "status": domain.ActiveOrderStatuses}).Prefix("SELECT EXISTS (").Suffix(")")
+
+	var exists bool
+	err := query.RunWith(transaction.Conn(ctx, o.Conn)).QueryRowContext(ctx).Scan(&exists)
+
+	return exists, err
 }
 
 func NewOrderRepository(conn *sql.DB) domain.OrderRepository {
 	return &orderRepository{Conn: conn, SqlBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar)}
 }
+
+// orderStatuses are values of OrderStatus enum type
+var orderStatuses = listing.EnumValues(domain.OrderStatuses)
+
+// orderColumns are fields of order available for sorting and filtering
+var orderColumns = listing.Columns{
+	"id":       {Name: "id", Kind: listing.KindInt},
+	"userId":   {Name: "user_id", Kind: listing.KindInt},
+	"petId":    {Name: "pet_id", Kind: listing.KindInt},
+	"shipDate": {Name: "ship_date", Kind: listing.KindTime},
+	"status":   {Name: "status", Kind: listing.KindEnum, Values: orderStatuses},
+	"complete": {Name: "complete", Kind: listing.KindBool},
+}
+
+func (o *orderRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Order, int, error) {
+	query := o.SqlBuilder.Select("id", "user_id", "pet_id", "ship_date", "status", "complete").From("orders")
+
+	return o.list(ctx, query, params)
+}
+
+func (o *orderRepository) ListByUser(ctx context.Context, userId int,
+	params *domain.ListParams) ([]*domain.Order, int, error) {
+	query := o.SqlBuilder.Select("id", "user_id", "pet_id", "ship_date", "status", "complete").From("orders")
+	query = query.Where(sq.Eq{"user_id": userId})
+
+	return o.list(ctx, query, params)
+}
+
+// list - apply list params to query of orders and run it.
+func (o *orderRepository) list(ctx context.Context, query sq.SelectBuilder,
+	params *domain.ListParams) ([]*domain.Order, int, error) {
+	return listing.Query(ctx, transaction.Conn(ctx, o.Conn), query, params, orderColumns, "id",
+		func(row sq.RowScanner) (*domain.Order, error) {
+			var item domain.Order
+			err := row.Scan(&item.Id, &item.UserId, &item.PetId, &item.ShipDate, &item.Status, &item.Complete)
+
+			return &item, err
+		})
+}
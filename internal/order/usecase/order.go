@@ -2,25 +2,246 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"go.uber.org/zap"
 	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"petstore/internal/tracing"
 )
 
+// orderTransitions are allowed transitions between order statuses.
+// Cancelled and returned orders are final.
+var orderTransitions = map[domain.OrderStatus][]domain.OrderStatus{
+	domain.PlacedOrderStatus:    {domain.ApprovedOrderStatus, domain.CancelledOrderStatus},
+	domain.ApprovedOrderStatus:  {domain.DeliveredOrderStatus, domain.CancelledOrderStatus},
+	domain.DeliveredOrderStatus: {domain.ReturnedOrderStatus},
+}
+
+func canTransition(from domain.OrderStatus, to domain.OrderStatus) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// petStatusOnTransition is status of pet after order moved to status.
+// Pet is reserved (pending) while order is placed or approved.
+var petStatusOnTransition = map[domain.OrderStatus]domain.PetStatus{
+	domain.DeliveredOrderStatus: domain.PetStatusSold,
+	domain.CancelledOrderStatus: domain.PetStatusAvailable,
+	domain.ReturnedOrderStatus:  domain.PetStatusAvailable,
+}
+
+// canChangeStatus - staff moves orders by any transition, owner can only cancel own order.
+func canChangeStatus(principal *domain.Principal, order *domain.Order, status domain.OrderStatus) bool {
+	if principal.Can(domain.PermissionOrderManage) {
+		return true
+	}
+
+	return order.UserId == principal.UserId && status == domain.CancelledOrderStatus
+}
+
+// isActive - pet of active order is reserved.
+func isActive(status domain.OrderStatus) bool {
+	return status == domain.PlacedOrderStatus || status == domain.ApprovedOrderStatus
+}
+
+// isComplete - order is complete when pet is delivered.
+func isComplete(status domain.OrderStatus) bool {
+	return status == domain.DeliveredOrderStatus
+}
+
 type orderUsecase struct {
 	orderRepo domain.OrderRepository
+	petRepo   domain.PetRepository
+	txManager domain.TxManager
+}
+
+func (o *orderUsecase) Get(ctx context.Context, id int, principal *domain.Principal) (order *domain.Order, err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.Get")
+	defer tracing.End(span, &err)
+
+	order, err = o.orderRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserId != principal.UserId && !principal.Can(domain.PermissionOrderReadAll) {
+		return nil, fmt.Errorf("%w: order belongs to another user", domain.ErrForbidden)
+	}
+
+	return order, nil
+}
+
+// Create - create order and reserve its pet, new order is always placed.
+// Return ErrPetNotAvailable if pet is already reserved or sold.
+func (o *orderUsecase) Create(ctx context.Context, order *domain.Order) (err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.Create")
+	defer tracing.End(span, &err)
+
+	order.Status = domain.PlacedOrderStatus
+	order.Complete = isComplete(order.Status)
+
+	return o.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := o.reservePet(ctx, order.PetId); err != nil {
+			return err
+		}
+
+		return o.orderRepo.Create(ctx, order)
+	})
+}
+
+// reservePet - move pet from available to pending.
+// Update is conditional, so only one of concurrent orders reserves the pet.
+func (o *orderUsecase) reservePet(ctx context.Context, petId int) error {
+	reserved, err := o.petRepo.UpdateStatusFrom(ctx, petId, domain.PetStatusAvailable, domain.PetStatusPending)
+	if err != nil {
+		return err
+	}
+
+	if reserved {
+		return nil
+	}
+
+	if _, err := o.petRepo.Get(ctx, petId); err != nil {
+		return err
+	}
+
+	return domain.ErrPetNotAvailable
 }
 
-func (o *orderUsecase) Get(ctx context.Context, id int) (*domain.Order, error) {
-	return o.orderRepo.Get(ctx, id)
+func (o *orderUsecase) UpdateStatus(ctx context.Context, id int, status domain.OrderStatus,
+	principal *domain.Principal) (order *domain.Order, err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.UpdateStatus")
+	defer tracing.End(span, &err)
+
+	err = o.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = o.orderRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !canChangeStatus(principal, order, status) {
+			return fmt.Errorf("%w: status of order can not be changed to %s", domain.ErrForbidden, status)
+		}
+
+		if !canTransition(order.Status, status) {
+			return fmt.Errorf("%w: from %s to %s", domain.ErrOrderTransition, order.Status, status)
+		}
+
+		logging.FromContext(ctx).Info("order status changed", zap.Int("orderId", order.Id),
+			zap.String("from", string(order.Status)), zap.String("to", string(status)))
+
+		order.Status = status
+		order.Complete = isComplete(status)
+
+		if err := o.orderRepo.UpdateStatus(ctx, order.Id, order.Status, order.Complete); err != nil {
+			return err
+		}
+
+		if petStatus, ok := petStatusOnTransition[status]; ok {
+			return o.petRepo.UpdateStatus(ctx, order.PetId, petStatus)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
 }
 
-func (o *orderUsecase) Create(ctx context.Context, order *domain.Order) error {
-	return o.orderRepo.Create(ctx, order)
+// Delete - delete order, pet of active order becomes available.
+func (o *orderUsecase) Delete(ctx context.Context, id int, principal *domain.Principal) (err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.Delete")
+	defer tracing.End(span, &err)
+
+	return o.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := o.orderRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.UserId != principal.UserId && !principal.Can(domain.PermissionOrderManage) {
+			return fmt.Errorf("%w: order belongs to another user", domain.ErrForbidden)
+		}
+
+		if err := o.orderRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if isActive(order.Status) {
+			return o.petRepo.UpdateStatus(ctx, order.PetId, domain.PetStatusAvailable)
+		}
+
+		return nil
+	})
 }
 
-func (o *orderUsecase) Delete(ctx context.Context, id int) error {
-	return o.orderRepo.Delete(ctx, id)
+func (o *orderUsecase) List(ctx context.Context, params *domain.ListParams,
+	principal *domain.Principal) (orders []*domain.Order, total int, err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.List")
+	defer tracing.End(span, &err)
+
+	if !principal.Can(domain.PermissionOrderReadAll) {
+		return o.orderRepo.ListByUser(ctx, principal.UserId, params)
+	}
+
+	return o.orderRepo.List(ctx, params)
+}
+
+func (o *orderUsecase) ListByUser(ctx context.Context, userId int,
+	params *domain.ListParams) (orders []*domain.Order, total int, err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.ListByUser")
+	defer tracing.End(span, &err)
+
+	return o.orderRepo.ListByUser(ctx, userId, params)
+}
+
+func (o *orderUsecase) GetInventory(ctx context.Context, byCategory bool) (inventory *domain.Inventory, err error) {
+	ctx, span := tracing.Start(ctx, "orderUsecase.GetInventory")
+	defer tracing.End(span, &err)
+
+	statuses, err := o.petRepo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory = &domain.Inventory{Statuses: withAllStatuses(statuses)}
+	if !byCategory {
+		return inventory, nil
+	}
+
+	categories, err := o.petRepo.CountByCategoryAndStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory.Categories = make(map[string]map[domain.PetStatus]int, len(categories))
+	for category, categoryStatuses := range categories {
+		inventory.Categories[category] = withAllStatuses(categoryStatuses)
+	}
+
+	return inventory, nil
+}
+
+// withAllStatuses - add zero count for statuses without pets.
+func withAllStatuses(counts map[domain.PetStatus]int) map[domain.PetStatus]int {
+	for _, status := range domain.PetStatuses {
+		if _, ok := counts[status]; !ok {
+			counts[status] = 0
+		}
+	}
+
+	return counts
 }
 
-func NewOrderUsecase(orderRepo domain.OrderRepository) domain.OrderUsecase {
-	return &orderUsecase{orderRepo: orderRepo}
+func NewOrderUsecase(orderRepo domain.OrderRepository, petRepo domain.PetRepository,
+	tm domain.TxManager) domain.OrderUsecase {
+	return &orderUsecase{orderRepo: orderRepo, petRepo: petRepo, txManager: tm}
 }
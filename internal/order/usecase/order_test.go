@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"petstore/internal/domain"
+	"petstore/internal/memory"
+	_orderRepository "petstore/internal/order/repository"
+	_petRepository "petstore/internal/pet/repository"
+	_userRepository "petstore/internal/user/repository"
+	"testing"
+)
+
+var (
+	owner    = &domain.Principal{UserId: 1, Username: "owner", Role: domain.RoleCustomer}
+	stranger = &domain.Principal{UserId: 2, Username: "stranger", Role: domain.RoleCustomer}
+	staff    = &domain.Principal{UserId: 3, Username: "staff", Role: domain.RoleStaff}
+)
+
+type fixture struct {
+	usecase domain.OrderUsecase
+	pets    domain.PetRepository
+	orders  domain.OrderRepository
+}
+
+// createPet create available pet in new category.
+func (f *fixture) createPet(t *testing.T, ctx context.Context, db *memory.DB) int {
+	t.Helper()
+
+	category := &domain.Category{Name: "dogs"}
+	if err := _petRepository.NewMemoryCategoryRepository(db).Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+
+	pet := &domain.PetDTO{CategoryId: category.Id, Name: "rex", Status: domain.PetStatusAvailable}
+	if err := f.pets.Create(ctx, pet); err != nil {
+		t.Fatal(err)
+	}
+
+	return pet.Id
+}
+
+// createUsers create users of principals, orders reference them.
+func createUsers(t *testing.T, ctx context.Context, db *memory.DB) {
+	t.Helper()
+
+	users := _userRepository.NewMemoryUserRepository(db)
+	for _, principal := range []*domain.Principal{owner, stranger, staff} {
+		user := &domain.User{Username: principal.Username, Role: principal.Role}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		if user.Id != principal.UserId {
+			t.Fatalf("got id %d of %s, want %d", user.Id, user.Username, principal.UserId)
+		}
+	}
+}
+
+// placeOrder create order of owner for new pet.
+func placeOrder(t *testing.T) (*fixture, *domain.Order) {
+	t.Helper()
+
+	ctx := context.Background()
+	db := memory.NewDB()
+	createUsers(t, ctx, db)
+	f := &fixture{
+		pets:   _petRepository.NewMemoryPetRepository(db),
+		orders: _orderRepository.NewMemoryOrderRepository(db),
+	}
+	f.usecase = NewOrderUsecase(f.orders, f.pets, memory.NewTxManager(db))
+
+	order := &domain.Order{UserId: owner.UserId, PetId: f.createPet(t, ctx, db)}
+	if err := f.usecase.Create(ctx, order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	return f, order
+}
+
+func TestCreateReservesPet(t *testing.T) {
+	ctx := context.Background()
+	f, order := placeOrder(t)
+
+	pet, err := f.pets.Get(ctx, order.PetId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pet.Status != domain.PetStatusPending {
+		t.Errorf("got pet status %s, want pending", pet.Status)
+	}
+
+	err = f.usecase.Create(ctx, &domain.Order{UserId: stranger.UserId, PetId: order.PetId})
+	if !errors.Is(err, domain.ErrPetNotAvailable) {
+		t.Errorf("second order of pet: got %v, want ErrPetNotAvailable", err)
+	}
+}
+
+func TestGetAndDeleteOfAnotherUser(t *testing.T) {
+	ctx := context.Background()
+	f, order := placeOrder(t)
+
+	if _, err := f.usecase.Get(ctx, order.Id, stranger); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("get order of another user: got %v, want ErrForbidden", err)
+	}
+
+	if _, err := f.usecase.Get(ctx, order.Id+1, owner); !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Errorf("get missing order: got %v, want ErrOrderNotFound", err)
+	}
+
+	if _, err := f.usecase.Get(ctx, order.Id, staff); err != nil {
+		t.Errorf("get order by staff: %v", err)
+	}
+
+	if err := f.usecase.Delete(ctx, order.Id, stranger); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("delete order of another user: got %v, want ErrForbidden", err)
+	}
+
+	if err := f.usecase.Delete(ctx, order.Id, owner); err != nil {
+		t.Fatalf("delete own order: %v", err)
+	}
+
+	if err := f.usecase.Delete(ctx, order.Id, owner); !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Errorf("delete missing order: got %v, want ErrOrderNotFound", err)
+	}
+
+	pet, err := f.pets.Get(ctx, order.PetId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pet.Status != domain.PetStatusAvailable {
+		t.Errorf("pet of deleted order: got status %s, want available", pet.Status)
+	}
+}
+
+func TestUpdateStatusPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		status    domain.OrderStatus
+		err       error
+	}{
+		{name: "owner approves", principal: owner, status: domain.ApprovedOrderStatus, err: domain.ErrForbidden},
+		{name: "stranger cancels", principal: stranger, status: domain.CancelledOrderStatus, err: domain.ErrForbidden},
+		{name: "owner cancels", principal: owner, status: domain.CancelledOrderStatus},
+		{name: "staff approves", principal: staff, status: domain.ApprovedOrderStatus},
+		{name: "staff delivers", principal: staff, status: domain.DeliveredOrderStatus, err: domain.ErrOrderTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, order := placeOrder(t)
+
+			updated, err := f.usecase.UpdateStatus(ctx, order.Id, tt.status, tt.principal)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("got %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("update status: %v", err)
+			}
+
+			if updated.Status != tt.status {
+				t.Errorf("got status %s, want %s", updated.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestListOfCustomerHasOnlyOwnOrders(t *testing.T) {
+	ctx := context.Background()
+	f, order := placeOrder(t)
+
+	params := &domain.ListParams{Page: 1, Limit: 20}
+
+	orders, total, err := f.usecase.List(ctx, params, stranger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 0 || len(orders) != 0 {
+		t.Errorf("stranger: got %d orders of %d, want none", len(orders), total)
+	}
+
+	// filter by user can not widen list of customer
+	filtered := &domain.ListParams{Page: 1, Limit: 20,
+		Filters: []domain.Filter{{Field: "userId", Operator: domain.FilterEq, Values: []string{"1"}}}}
+	if orders, _, err := f.usecase.List(ctx, filtered, stranger); err != nil || len(orders) != 0 {
+		t.Errorf("stranger with userId filter: got %d orders, error %v", len(orders), err)
+	}
+
+	for _, principal := range []*domain.Principal{owner, staff} {
+		orders, total, err := f.usecase.List(ctx, params, principal)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if total != 1 || len(orders) != 1 || orders[0].Id != order.Id {
+			t.Errorf("%s: got %d orders of %d, want order %d", principal.Username, len(orders), total, order.Id)
+		}
+	}
+}
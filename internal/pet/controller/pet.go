@@ -1,16 +1,22 @@
 package controller
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi"
 	"net/http"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
 	"petstore/internal/responder"
+	"petstore/internal/user/controller/middleware"
+	"petstore/internal/validation"
 	"strconv"
+	"strings"
 )
 
+// maxImageSize is max size of uploaded image in bytes
+const maxImageSize = 10 << 20
+
 type petController struct {
 	responder  responder.Responder
 	petUsecase domain.PetUsecase
@@ -24,31 +30,41 @@ func NewPetController(r chi.Router, responder responder.Responder, pu domain.Pet
 
 	r.Route("/pet", func(r chi.Router) {
 		r.Get("/{petId}", controller.Get)
-		r.Post("/", controller.Create)
-		r.Put("/", controller.Update)
-		r.Delete("/{petId}", controller.Delete)
-
 		r.Get("/findByStatus", controller.FindByStatus)
+		r.Get("/findByTags", controller.FindByTags)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequirePermission(responder, domain.PermissionPetWrite))
+
+			r.Post("/", controller.Create)
+			r.Put("/", controller.Update)
+			r.Delete("/{petId}", controller.Delete)
+			r.Post("/{petId}/uploadImage", controller.UploadImage)
+		})
 	})
+
+	r.Get("/category", controller.ListCategories)
+	r.Get("/tag", controller.ListTags)
 }
 
 // Create this function is used to create a new pet in the store.
 //
 // @Summary		Add a new pet to the store
 // @Tags		pet
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		pet		body		domain.Pet			true	"Pet object that needs to be added to the store"
 //
 // @Success		200		{object}	domain.Pet			"Pet object that was added"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Router		/pet 	[post]
 func (p *petController) Create(w http.ResponseWriter, r *http.Request) {
 	var petInput domain.Pet
 
-	err := json.NewDecoder(r.Body).Decode(&petInput)
+	err := validation.Decode(w, r, &petInput)
 	if err != nil {
 		p.responder.ErrorBadRequest(w, err)
 		return
@@ -56,11 +72,11 @@ func (p *petController) Create(w http.ResponseWriter, r *http.Request) {
 
 	err = p.petUsecase.Create(r.Context(), &petInput)
 	if err != nil {
-		p.responder.ErrorInternal(w, err)
+		p.responder.Error(w, err)
 		return
 	}
 
-	p.responder.OutputJSON(w, responder.Response{
+	p.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "pet created",
 		Data:    petInput,
@@ -71,7 +87,7 @@ func (p *petController) Create(w http.ResponseWriter, r *http.Request) {
 //
 // @Summary		Get a pet by ID
 // @Tags		pet
-// @Produce		json
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 //	@Param		petId	path		int				true	"ID of pet to return"
@@ -83,28 +99,23 @@ func (p *petController) Create(w http.ResponseWriter, r *http.Request) {
 func (p *petController) Get(w http.ResponseWriter, r *http.Request) {
 	petId := chi.URLParam(r, "petId")
 	if petId == "" {
-		p.responder.ErrorBadRequest(w, fmt.Errorf("param petId is required"))
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId is required")))
 		return
 	}
 
 	petIdInt, err := strconv.Atoi(petId)
 	if err != nil {
-		p.responder.ErrorBadRequest(w, fmt.Errorf("param petId must be integer"))
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId must be integer")))
 		return
 	}
 
 	pet, err := p.petUsecase.Get(r.Context(), petIdInt)
 	if err != nil {
-		if errors.Is(err, domain.ErrPetNotFound) {
-			p.responder.ErrorNotFound(w, err)
-		} else {
-			p.responder.ErrorInternal(w, err)
-		}
-
+		p.responder.Error(w, err)
 		return
 	}
 
-	p.responder.OutputJSON(w, responder.Response{
+	p.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "get pet",
 		Data:    pet,
@@ -115,20 +126,22 @@ func (p *petController) Get(w http.ResponseWriter, r *http.Request) {
 //
 // @Summary		Update a pet in the store with form data
 // @Tags		pet
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		pet		body		domain.Pet			true	"Pet object that needs to update"
 //
 // @Success		200		{string}	string				"Pet updated"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"Pet not found"
+// @Failure		409		{string}	string				"Status of pet with active order can not be changed"
 // @Router		/pet 	[put]
 func (p *petController) Update(w http.ResponseWriter, r *http.Request) {
 	var petInput domain.Pet
 
-	err := json.NewDecoder(r.Body).Decode(&petInput)
+	err := validation.Decode(w, r, &petInput)
 	if err != nil {
 		p.responder.ErrorBadRequest(w, err)
 		return
@@ -136,11 +149,11 @@ func (p *petController) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = p.petUsecase.Update(r.Context(), &petInput)
 	if err != nil {
-		p.responder.ErrorInternal(w, err)
+		p.responder.Error(w, err)
 		return
 	}
 
-	p.responder.OutputJSON(w, responder.Response{
+	p.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "pet updated",
 		Data:    nil,
@@ -151,40 +164,37 @@ func (p *petController) Update(w http.ResponseWriter, r *http.Request) {
 //
 // @Summary		Delete a pet by ID
 // @Tags		pet
-// @Produce		json
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		petId	path		int				true	"ID of pet to delete"
 //
 // @Success		200		{string}	string				"Pet deleted"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"Pet not found"
+// @Failure		409		{string}	string				"Pet has orders"
 // @Router		/pet/{petId} 		[delete]
 func (p *petController) Delete(w http.ResponseWriter, r *http.Request) {
 	petId := chi.URLParam(r, "petId")
 	if petId == "" {
-		p.responder.ErrorBadRequest(w, fmt.Errorf("param petId is required"))
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId is required")))
 		return
 	}
 
 	petIdInt, err := strconv.Atoi(petId)
 	if err != nil {
-		p.responder.ErrorBadRequest(w, fmt.Errorf("param petId must be integer"))
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId must be integer")))
 		return
 	}
 
 	err = p.petUsecase.Delete(r.Context(), petIdInt)
 	if err != nil {
-		if errors.Is(err, domain.ErrPetNotFound) {
-			p.responder.ErrorNotFound(w, err)
-		} else {
-			p.responder.ErrorInternal(w, err)
-		}
-
+		p.responder.Error(w, err)
 		return
 	}
 
-	p.responder.OutputJSON(w, responder.Response{
+	p.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "get deleted",
 		Data:    nil,
@@ -193,12 +203,16 @@ func (p *petController) Delete(w http.ResponseWriter, r *http.Request) {
 
 // FindByStatus this function is used to get a pet from the store by pet status.
 //
-// @Summary		Delete a pet by ID
+// @Summary		Finds pets by status
 // @Tags		pet
-// @Produce		json
+// @Produce		json,application/xml
 // @Security 	ApiKeyAuth
 //
 // @Param		status	query		string				true	"Status values that need to be considered for filter"
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
 //
 // @Success		200		{object}	[]domain.Pet		"Pets found by status"
 // @Failure		400		{string}	string				"Invalid input"
@@ -206,25 +220,220 @@ func (p *petController) Delete(w http.ResponseWriter, r *http.Request) {
 // @Router		/pet/findByStatus 		[get]
 func (p *petController) FindByStatus(w http.ResponseWriter, r *http.Request) {
 	if !r.URL.Query().Has("status") {
-		p.responder.ErrorBadRequest(w, fmt.Errorf("param status is required"))
+		p.responder.ErrorBadRequest(w, domain.FieldError("status", fmt.Errorf("param status is required")))
 		return
 	}
 
 	status, err := domain.PetStatusFromString(r.URL.Query().Get("status"))
+	if err != nil {
+		p.responder.ErrorBadRequest(w, domain.FieldError("status", err))
+		return
+	}
+
+	params, err := listing.ParseParams(r)
 	if err != nil {
 		p.responder.ErrorBadRequest(w, err)
 		return
 	}
 
-	pets, err := p.petUsecase.GetByStatus(r.Context(), status)
+	pets, total, err := p.petUsecase.GetByStatus(r.Context(), status, params)
+	if err != nil {
+		p.responder.Error(w, err)
+		return
+	}
+
+	p.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "find pet by status",
+		Data:       pets,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
+// UploadImage this function is used to upload an image of pet.
+//
+// @Summary		Uploads an image
+// @Tags		pet
+// @Accept		multipart/form-data
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		petId	path		int					true	"ID of pet to update"
+// @Param		file	formData	file				true	"Image to upload: jpeg, png, webp or gif"
+//
+// @Success		200		{string}	string				"Public URL of uploaded image"
+// @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
+// @Failure		404		{string}	string				"Pet not found"
+// @Failure		413		{string}	string				"Image is too large"
+// @Router		/pet/{petId}/uploadImage 		[post]
+func (p *petController) UploadImage(w http.ResponseWriter, r *http.Request) {
+	petId := chi.URLParam(r, "petId")
+	if petId == "" {
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId is required")))
+		return
+	}
+
+	petIdInt, err := strconv.Atoi(petId)
+	if err != nil {
+		p.responder.ErrorBadRequest(w, domain.FieldError("petId", fmt.Errorf("param petId must be integer")))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize)
+	file, _, err := r.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		p.responder.ErrorRequestEntityTooLarge(w, domain.FieldError("file",
+			fmt.Errorf("%w: image must not be larger than %d bytes", domain.ErrInvalidBody, maxBytesErr.Limit)))
+		return
+	}
 	if err != nil {
-		p.responder.ErrorInternal(w, err)
+		p.responder.ErrorBadRequest(w, domain.FieldError("file", fmt.Errorf("failed read file: %w", err)))
 		return
 	}
+	defer file.Close()
 
-	p.responder.OutputJSON(w, responder.Response{
+	photo := domain.PhotoDTO{PetId: petIdInt}
+	err = p.petUsecase.UploadImage(r.Context(), &photo, file)
+	if err != nil {
+		p.responder.Error(w, err)
+		return
+	}
+
+	p.responder.Output(w, responder.Response{
 		Success: true,
-		Message: "find pet by status",
-		Data:    pets,
+		Message: "image uploaded",
+		Data:    photo.GetPublicURL(),
+	})
+}
+
+// FindByTags this function is used to get pets from the store by tags.
+//
+// @Summary		Finds pets by tags
+// @Tags		pet
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		tags	query		[]string			true	"Tags to filter by, comma separated or repeated"	collectionFormat(multi)
+// @Param		match	query		string				false	"Match any of tags or all of them"	Enums(any, all)	default(any)
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.Pet		"Pets found by tags"
+// @Failure		400		{string}	string				"Invalid input"
+// @Router		/pet/findByTags 		[get]
+func (p *petController) FindByTags(w http.ResponseWriter, r *http.Request) {
+	tags := make([]string, 0)
+	for _, param := range r.URL.Query()["tags"] {
+		for _, tag := range strings.Split(param, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	if len(tags) == 0 {
+		p.responder.ErrorBadRequest(w, domain.FieldError("tags", fmt.Errorf("param tags is required")))
+		return
+	}
+
+	match, err := domain.TagMatchFromString(r.URL.Query().Get("match"))
+	if err != nil {
+		p.responder.ErrorBadRequest(w, domain.FieldError("match", err))
+		return
+	}
+
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		p.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	pets, total, err := p.petUsecase.GetByTags(r.Context(), tags, match, params)
+	if err != nil {
+		p.responder.Error(w, err)
+		return
+	}
+
+	p.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "find pet by tags",
+		Data:       pets,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
+// ListCategories this function is used to get categories of pets.
+//
+// @Summary		List categories of pets
+// @Tags		pet
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.Category	"Page of categories"
+// @Failure		400		{string}	string				"Invalid input"
+// @Router		/category 		[get]
+func (p *petController) ListCategories(w http.ResponseWriter, r *http.Request) {
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		p.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	categories, total, err := p.petUsecase.ListCategories(r.Context(), params)
+	if err != nil {
+		p.responder.Error(w, err)
+		return
+	}
+
+	p.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "list categories",
+		Data:       categories,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
+// ListTags this function is used to get tags of pets.
+//
+// @Summary		List tags of pets
+// @Tags		pet
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.Tag		"Page of tags"
+// @Failure		400		{string}	string				"Invalid input"
+// @Router		/tag 		[get]
+func (p *petController) ListTags(w http.ResponseWriter, r *http.Request) {
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		p.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	tags, total, err := p.petUsecase.ListTags(r.Context(), params)
+	if err != nil {
+		p.responder.Error(w, err)
+		return
+	}
+
+	p.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "list tags",
+		Data:       tags,
+		Pagination: responder.NewPagination(params, total),
 	})
 }
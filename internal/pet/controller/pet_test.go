@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-chi/chi"
+	"github.com/ptflp/godecoder"
+	"go.uber.org/zap"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"petstore/internal/domain"
+	"petstore/internal/responder"
+	"testing"
+)
+
+// stubPetUsecase accepts any uploaded image.
+type stubPetUsecase struct {
+	domain.PetUsecase
+}
+
+func (stubPetUsecase) UploadImage(_ context.Context, photo *domain.PhotoDTO, _ io.Reader) error {
+	photo.Id = 1
+	return nil
+}
+
+// newTestRouter register routes of pets for staff user.
+func newTestRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := &domain.Principal{UserId: 1, Role: domain.RoleStaff}
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(r.Context(), principal)))
+		})
+	})
+	NewPetController(r, responder.NewResponder(godecoder.NewDecoder(), zap.NewNop()), stubPetUsecase{})
+
+	return r
+}
+
+// newUploadRequest create multipart request with file of size bytes.
+func newUploadRequest(t *testing.T, size int) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "pet.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Write(make([]byte, size)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/pet/1/uploadImage", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return req
+}
+
+func TestUploadImageSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		status int
+	}{
+		{name: "small", size: 1 << 10, status: http.StatusOK},
+		{name: "too large", size: maxImageSize + 1, status: http.StatusRequestEntityTooLarge},
+	}
+
+	r := newTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, newUploadRequest(t, tt.size))
+
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
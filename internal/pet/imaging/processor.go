@@ -0,0 +1,125 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"petstore/internal/domain"
+
+	_ "golang.org/x/image/webp"
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// jpegQuality is quality of all encoded images
+	jpegQuality = 90
+
+	// maxPixels is max width * height of uploaded image.
+	// Protect from images which take too much memory after decoding.
+	maxPixels = 50_000_000
+)
+
+type imageProcessor struct {
+	thumbnails []domain.ThumbnailSize
+}
+
+func NewImageProcessor(thumbnails []domain.ThumbnailSize) domain.ImageProcessor {
+	return &imageProcessor{thumbnails: thumbnails}
+}
+
+func (i *imageProcessor) Thumbnails() []domain.ThumbnailSize {
+	return i.thumbnails
+}
+
+func (i *imageProcessor) Process(data []byte) (*domain.ProcessedImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrInvalidImage
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("%w: image is too large", domain.ErrInvalidImage)
+	}
+
+	// for gif only first frame is decoded
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrInvalidImage
+	}
+
+	// metadata is lost on encode, so orientation must be applied to pixels
+	if format == "jpeg" {
+		img = applyOrientation(img, readOrientation(data))
+	}
+
+	img = flatten(img)
+
+	original, err := encode(img)
+	if err != nil {
+		return nil, err
+	}
+
+	processed := &domain.ProcessedImage{
+		Original:   original,
+		Thumbnails: make(map[string][]byte, len(i.thumbnails)),
+	}
+
+	for _, thumbnail := range i.thumbnails {
+		thumbnailData, err := encode(resize(img, thumbnail.Size))
+		if err != nil {
+			return nil, err
+		}
+
+		processed.Thumbnails[thumbnail.Name] = thumbnailData
+	}
+
+	return processed, nil
+}
+
+// flatten - draw image on white background, because jpeg has no transparency.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// resize - scale image to fit in size x size square with saving proportions.
+// Small images are not upscaled.
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width > height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,287 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"petstore/internal/domain"
+	"testing"
+)
+
+var testThumbnails = []domain.ThumbnailSize{{Name: "small", Size: 100}, {Name: "medium", Size: 300}}
+
+// 1x1 lossless webp, there is no webp encoder in standard or x/image packages
+const webpBase64 = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+
+func TestProcessJpegWithExif(t *testing.T) {
+	data := withExif(t, encodeJpeg(t, testImage(400, 300)), 1)
+	if !hasApp1(data) {
+		t.Fatal("test image has no APP1 segment")
+	}
+
+	processed, err := NewImageProcessor(testThumbnails).Process(data)
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	assertRendition(t, "original", processed.Original, 400, 300)
+	assertRendition(t, "small", processed.Thumbnails["small"], 100, 75)
+	assertRendition(t, "medium", processed.Thumbnails["medium"], 300, 225)
+
+	if len(processed.Thumbnails) != len(testThumbnails) {
+		t.Errorf("got %d thumbnails, want %d", len(processed.Thumbnails), len(testThumbnails))
+	}
+}
+
+func TestProcessSmallImageIsNotUpscaled(t *testing.T) {
+	processed, err := NewImageProcessor(testThumbnails).Process(encodeJpeg(t, testImage(50, 80)))
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	assertRendition(t, "small", processed.Thumbnails["small"], 50, 80)
+}
+
+func TestProcessFormats(t *testing.T) {
+	img := testImage(40, 20)
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	webpData, err := base64.StdEncoding.DecodeString(webpBase64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		data          []byte
+		width, height int
+	}{
+		{name: "png", data: pngData.Bytes(), width: 40, height: 20},
+		{name: "gif", data: gifData.Bytes(), width: 40, height: 20},
+		{name: "webp", data: webpData, width: 1, height: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := NewImageProcessor(testThumbnails).Process(tt.data)
+			if err != nil {
+				t.Fatalf("process: %v", err)
+			}
+
+			assertRendition(t, "original", processed.Original, tt.width, tt.height)
+		})
+	}
+}
+
+func TestProcessInvalidImage(t *testing.T) {
+	_, err := NewImageProcessor(testThumbnails).Process([]byte("not an image"))
+	if !errors.Is(err, domain.ErrInvalidImage) {
+		t.Errorf("got %v, want ErrInvalidImage", err)
+	}
+}
+
+// TestProcessMalformedSegments - invalid segments before body of jpeg have no orientation and don't panic.
+func TestProcessMalformedSegments(t *testing.T) {
+	body := encodeJpeg(t, testImage(64, 32))
+
+	tests := []struct {
+		name    string
+		segment []byte
+		err     error
+	}{
+		// decoder skips junk after standalone marker, so image is valid
+		{name: "standalone RST0 with junk", segment: []byte{0xFF, 0xD0, 0x00, 0x00}},
+		{name: "length less than 2", segment: []byte{0xFF, 0xE0, 0x00, 0x01}, err: domain.ErrInvalidImage},
+		{name: "length beyond end", segment: []byte{0xFF, 0xE1, 0xFF, 0xFF}, err: domain.ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(append(append([]byte{}, body[:2]...), tt.segment...), body[2:]...)
+
+			if orientation := readOrientation(data); orientation != 1 {
+				t.Errorf("got orientation %d, want 1", orientation)
+			}
+
+			if _, err := NewImageProcessor(testThumbnails).Process(data); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	// left half is red, right half is blue
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			if x < 32 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		// color of points of processed image: red is true, blue is false
+		points map[image.Point]bool
+	}{
+		{orientation: 1, width: 64, height: 32, points: map[image.Point]bool{{8, 16}: true, {56, 16}: false}},
+		{orientation: 2, width: 64, height: 32, points: map[image.Point]bool{{8, 16}: false, {56, 16}: true}},
+		{orientation: 3, width: 64, height: 32, points: map[image.Point]bool{{8, 16}: false, {56, 16}: true}},
+		// rotated 90 degrees clockwise
+		{orientation: 6, width: 32, height: 64, points: map[image.Point]bool{{16, 8}: true, {16, 56}: false}},
+		// rotated 90 degrees counterclockwise
+		{orientation: 8, width: 32, height: 64, points: map[image.Point]bool{{16, 8}: false, {16, 56}: true}},
+	}
+
+	for _, tt := range tests {
+		data := withExif(t, encodeJpeg(t, img), tt.orientation)
+		if got := readOrientation(data); got != tt.orientation {
+			t.Errorf("orientation %d: read %d", tt.orientation, got)
+			continue
+		}
+
+		processed, err := NewImageProcessor(nil).Process(data)
+		if err != nil {
+			t.Fatalf("orientation %d: process: %v", tt.orientation, err)
+		}
+
+		result := assertRendition(t, "original", processed.Original, tt.width, tt.height)
+		if result == nil {
+			continue
+		}
+
+		for point, red := range tt.points {
+			r, _, b, _ := result.At(point.X, point.Y).RGBA()
+			if (r > b) != red {
+				t.Errorf("orientation %d: unexpected color at %v", tt.orientation, point)
+			}
+		}
+	}
+}
+
+// assertRendition checks that data is jpeg of width x height without APP1 segment, returns decoded image.
+func assertRendition(t *testing.T, name string, data []byte, width, height int) image.Image {
+	t.Helper()
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Errorf("%s: decode: %v", name, err)
+		return nil
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != width || bounds.Dy() != height {
+		t.Errorf("%s: got size %dx%d, want %dx%d", name, bounds.Dx(), bounds.Dy(), width, height)
+	}
+
+	if hasApp1(data) {
+		t.Errorf("%s: metadata is not stripped", name)
+	}
+
+	return img
+}
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	return img
+}
+
+func encodeJpeg(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// withExif inserts APP1 segment with orientation and GPS position after SOI marker of jpeg.
+func withExif(t *testing.T, data []byte, orientation int) []byte {
+	t.Helper()
+
+	order := binary.BigEndian
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, order, uint16(42))
+	// offset of IFD0
+	binary.Write(&tiff, order, uint32(8))
+
+	// IFD0: orientation and pointer to GPS IFD, which follows IFD0
+	const gpsIfdOffset = 8 + 2 + 2*12 + 4
+	binary.Write(&tiff, order, uint16(2))
+	writeIfdEntry(&tiff, 0x0112, 3, 1, uint32(orientation)<<16)
+	writeIfdEntry(&tiff, 0x8825, 4, 1, gpsIfdOffset)
+	binary.Write(&tiff, order, uint32(0))
+
+	// GPS IFD: latitude reference "N" and longitude reference "E"
+	binary.Write(&tiff, order, uint16(2))
+	writeIfdEntry(&tiff, 0x0001, 2, 2, uint32('N')<<24)
+	writeIfdEntry(&tiff, 0x0003, 2, 2, uint32('E')<<24)
+	binary.Write(&tiff, order, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	var result bytes.Buffer
+	result.Write(data[:2])
+	result.Write([]byte{0xFF, 0xE1})
+	if err := binary.Write(&result, order, uint16(len(payload)+2)); err != nil {
+		t.Fatal(err)
+	}
+	result.Write(payload)
+	result.Write(data[2:])
+
+	return result.Bytes()
+}
+
+func writeIfdEntry(buf *bytes.Buffer, tag, valueType uint16, count, value uint32) {
+	binary.Write(buf, binary.BigEndian, tag)
+	binary.Write(buf, binary.BigEndian, valueType)
+	binary.Write(buf, binary.BigEndian, count)
+	binary.Write(buf, binary.BigEndian, value)
+}
+
+// hasApp1 returns true if jpeg has APP1 segment, which contains EXIF or XMP metadata.
+func hasApp1(data []byte) bool {
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA {
+			return false
+		}
+
+		if marker == 0xE1 {
+			return true
+		}
+
+		pos += 2 + int(binary.BigEndian.Uint16(data[pos+2:pos+4]))
+	}
+
+	return false
+}
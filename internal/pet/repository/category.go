@@ -6,6 +6,8 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/transaction"
 )
 
 type categoryRepository struct {
@@ -35,7 +37,7 @@ func (c *categoryRepository) GetElseCreate(ctx context.Context, category *domain
 
 func (c *categoryRepository) GetByName(ctx context.Context, name string) (*domain.Category, error) {
 	query := c.SqlBuilder.Select("id", "name").From("categories").Where(sq.Eq{"name": name})
-	row := query.RunWith(c.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, c.Conn)).QueryRowContext(ctx)
 
 	var category domain.Category
 	err := row.Scan(&category.Id, &category.Name)
@@ -54,7 +56,7 @@ func NewCategoryRepository(conn *sql.DB) domain.CategoryRepository {
 func (c *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
 	query := c.SqlBuilder.Insert("categories").Columns("name").Values(category.Name)
 	query = query.Suffix("RETURNING id")
-	row := query.RunWith(c.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, c.Conn)).QueryRowContext(ctx)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
@@ -68,7 +70,7 @@ func (c *categoryRepository) Create(ctx context.Context, category *domain.Catego
 
 func (c *categoryRepository) Get(ctx context.Context, id int) (*domain.Category, error) {
 	query := c.SqlBuilder.Select("id", "name").From("categories").Where(sq.Eq{"id": id})
-	row := query.RunWith(c.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, c.Conn)).QueryRowContext(ctx)
 
 	var category domain.Category
 	err := row.Scan(&category.Id, &category.Name)
@@ -79,3 +81,46 @@ func (c *categoryRepository) Get(ctx context.Context, id int) (*domain.Category,
 
 	return &category, err
 }
+
+func (c *categoryRepository) GetByIds(ctx context.Context, ids []int) (map[int]*domain.Category, error) {
+	categories := make(map[int]*domain.Category, len(ids))
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	query := c.SqlBuilder.Select("id", "name").From("categories").Where(sq.Eq{"id": ids})
+	rows, err := transaction.Conn(ctx, c.Conn).QueryRows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category domain.Category
+		if err := rows.Scan(&category.Id, &category.Name); err != nil {
+			return nil, err
+		}
+
+		categories[category.Id] = &category
+	}
+
+	return categories, rows.Err()
+}
+
+// categoryColumns are fields of category available for sorting and filtering
+var categoryColumns = listing.Columns{
+	"id":   {Name: "id", Kind: listing.KindInt},
+	"name": {Name: "name", Kind: listing.KindString},
+}
+
+func (c *categoryRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Category, int, error) {
+	query := c.SqlBuilder.Select("id", "name").From("categories")
+
+	return listing.Query(ctx, transaction.Conn(ctx, c.Conn), query, params, categoryColumns, "id",
+		func(row sq.RowScanner) (*domain.Category, error) {
+			var item domain.Category
+			err := row.Scan(&item.Id, &item.Name)
+
+			return &item, err
+		})
+}
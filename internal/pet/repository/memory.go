@@ -0,0 +1,590 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/memory"
+)
+
+// petFields are fields of pet available for sorting and filtering in memory, like petColumns
+var petFields = listing.Fields[domain.PetDTO]{
+	"id":         {Kind: listing.KindInt, Value: func(p domain.PetDTO) interface{} { return p.Id }},
+	"name":       {Kind: listing.KindString, Value: func(p domain.PetDTO) interface{} { return p.Name }},
+	"status":     {Kind: listing.KindEnum, Value: func(p domain.PetDTO) interface{} { return string(p.Status) }, Values: petStatuses},
+	"categoryId": {Kind: listing.KindInt, Value: func(p domain.PetDTO) interface{} { return p.CategoryId }},
+}
+
+type memoryPetRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryPetRepository(db *memory.DB) domain.PetRepository {
+	return &memoryPetRepository{DB: db}
+}
+
+func (p *memoryPetRepository) Get(ctx context.Context, id int) (*domain.PetDTO, error) {
+	var pet domain.PetDTO
+	err := p.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if pet, ok = tables.Pets.Get(id); !ok {
+			return domain.ErrPetNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pet, nil
+}
+
+// GetForUpdate is the same as Get, transactions in memory are serialized.
+func (p *memoryPetRepository) GetForUpdate(ctx context.Context, id int) (*domain.PetDTO, error) {
+	return p.Get(ctx, id)
+}
+
+func (p *memoryPetRepository) Create(ctx context.Context, pet *domain.PetDTO) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Categories.Get(pet.CategoryId); !ok {
+			return domain.ErrCategoryNotFound
+		}
+
+		pet.Id = tables.Pets.NextId()
+		tables.Pets.Put(pet.Id, *pet)
+
+		return nil
+	})
+}
+
+func (p *memoryPetRepository) Update(ctx context.Context, pet *domain.PetDTO) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Pets.Get(pet.Id); !ok {
+			return domain.ErrPetNotFound
+		}
+
+		if _, ok := tables.Categories.Get(pet.CategoryId); !ok {
+			return domain.ErrCategoryNotFound
+		}
+
+		tables.Pets.Put(pet.Id, *pet)
+		return nil
+	})
+}
+
+func (p *memoryPetRepository) Delete(ctx context.Context, id int) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Pets.Get(id); !ok {
+			return domain.ErrPetNotFound
+		}
+
+		// foreign keys of postgres
+		if _, ok := tables.Orders.Find(func(order domain.Order) bool { return order.PetId == id }); ok {
+			return domain.ErrPetHasOrders
+		}
+
+		_, hasTags := tables.PetTags.Find(func(petTag memory.PetTag) bool { return petTag.PetId == id })
+		_, hasPhotos := tables.Photos.Find(func(photo domain.PhotoDTO) bool { return photo.PetId == id })
+		if hasTags || hasPhotos {
+			return fmt.Errorf("pet %d is referenced by tags or photos", id)
+		}
+
+		tables.Pets.Delete(id)
+		return nil
+	})
+}
+
+func (p *memoryPetRepository) GetByStatus(ctx context.Context, status domain.PetStatus,
+	params *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	return p.list(ctx, params, func(tables *memory.Tables) []domain.PetDTO {
+		pets := make([]domain.PetDTO, 0)
+		for _, pet := range tables.Pets.Rows() {
+			if pet.Status == status {
+				pets = append(pets, pet)
+			}
+		}
+
+		return pets
+	})
+}
+
+func (p *memoryPetRepository) GetByTags(ctx context.Context, tags []string, match domain.TagMatch,
+	params *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	names := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		names[tag] = true
+	}
+
+	return p.list(ctx, params, func(tables *memory.Tables) []domain.PetDTO {
+		// matched is set of ids of matched tags of each pet
+		matched := make(map[int]map[int]bool)
+		for _, petTag := range tables.PetTags.Rows() {
+			if tag, ok := tables.Tags.Get(petTag.TagId); ok && names[tag.Name] {
+				if matched[petTag.PetId] == nil {
+					matched[petTag.PetId] = make(map[int]bool)
+				}
+				matched[petTag.PetId][tag.Id] = true
+			}
+		}
+
+		pets := make([]domain.PetDTO, 0)
+		for _, pet := range tables.Pets.Rows() {
+			count := len(matched[pet.Id])
+			if count > 0 && (match != domain.TagMatchAll || count == countUnique(tags)) {
+				pets = append(pets, pet)
+			}
+		}
+
+		return pets
+	})
+}
+
+// list - apply list params to pets returned by fn.
+// Returns page of pets and total number of pets.
+func (p *memoryPetRepository) list(ctx context.Context, params *domain.ListParams,
+	fn func(tables *memory.Tables) []domain.PetDTO) ([]*domain.PetDTO, int, error) {
+	var page []domain.PetDTO
+	var total int
+	err := p.DB.Read(ctx, func(tables *memory.Tables) error {
+		var err error
+		page, total, err = listing.List(fn(tables), params, petFields)
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pointers(page), total, nil
+}
+
+func (p *memoryPetRepository) UpdateStatusFrom(ctx context.Context, id int, from domain.PetStatus,
+	to domain.PetStatus) (bool, error) {
+	isUpdate := false
+	err := p.DB.Write(ctx, func(tables *memory.Tables) error {
+		pet, ok := tables.Pets.Get(id)
+		if !ok || pet.Status != from {
+			return nil
+		}
+
+		pet.Status = to
+		tables.Pets.Put(id, pet)
+		isUpdate = true
+
+		return nil
+	})
+
+	return isUpdate, err
+}
+
+func (p *memoryPetRepository) UpdateStatus(ctx context.Context, id int, status domain.PetStatus) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		pet, ok := tables.Pets.Get(id)
+		if !ok {
+			return domain.ErrPetNotFound
+		}
+
+		pet.Status = status
+		tables.Pets.Put(id, pet)
+
+		return nil
+	})
+}
+
+func (p *memoryPetRepository) CountByStatus(ctx context.Context) (map[domain.PetStatus]int, error) {
+	counts := make(map[domain.PetStatus]int)
+	err := p.DB.Read(ctx, func(tables *memory.Tables) error {
+		for _, pet := range tables.Pets.Rows() {
+			if pet.Status != "" {
+				counts[pet.Status]++
+			}
+		}
+
+		return nil
+	})
+
+	return counts, err
+}
+
+func (p *memoryPetRepository) CountByCategoryAndStatus(ctx context.Context) (map[string]map[domain.PetStatus]int, error) {
+	counts := make(map[string]map[domain.PetStatus]int)
+	err := p.DB.Read(ctx, func(tables *memory.Tables) error {
+		for _, pet := range tables.Pets.Rows() {
+			category, ok := tables.Categories.Get(pet.CategoryId)
+			if !ok || pet.Status == "" {
+				continue
+			}
+
+			if counts[category.Name] == nil {
+				counts[category.Name] = make(map[domain.PetStatus]int)
+			}
+			counts[category.Name][pet.Status]++
+		}
+
+		return nil
+	})
+
+	return counts, err
+}
+
+// categoryFields are fields of category available for sorting and filtering in memory, like categoryColumns
+var categoryFields = listing.Fields[domain.Category]{
+	"id":   {Kind: listing.KindInt, Value: func(c domain.Category) interface{} { return c.Id }},
+	"name": {Kind: listing.KindString, Value: func(c domain.Category) interface{} { return c.Name }},
+}
+
+type memoryCategoryRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryCategoryRepository(db *memory.DB) domain.CategoryRepository {
+	return &memoryCategoryRepository{DB: db}
+}
+
+func (c *memoryCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	return c.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := findByName(tables.Categories, category.Name, categoryName); ok {
+			return fmt.Errorf("category %s already exists", category.Name)
+		}
+
+		category.Id = tables.Categories.NextId()
+		tables.Categories.Put(category.Id, *category)
+
+		return nil
+	})
+}
+
+func (c *memoryCategoryRepository) Get(ctx context.Context, id int) (*domain.Category, error) {
+	var category domain.Category
+	err := c.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if category, ok = tables.Categories.Get(id); !ok {
+			return domain.ErrCategoryNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (c *memoryCategoryRepository) GetByIds(ctx context.Context, ids []int) (map[int]*domain.Category, error) {
+	categories := make(map[int]*domain.Category, len(ids))
+	err := c.DB.Read(ctx, func(tables *memory.Tables) error {
+		for _, id := range ids {
+			if category, ok := tables.Categories.Get(id); ok {
+				categories[id] = &category
+			}
+		}
+
+		return nil
+	})
+
+	return categories, err
+}
+
+func (c *memoryCategoryRepository) GetByName(ctx context.Context, name string) (*domain.Category, error) {
+	var category domain.Category
+	err := c.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if category, ok = findByName(tables.Categories, name, categoryName); !ok {
+			return domain.ErrCategoryNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (c *memoryCategoryRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Category, int, error) {
+	var page []domain.Category
+	var total int
+	err := c.DB.Read(ctx, func(tables *memory.Tables) error {
+		var err error
+		page, total, err = listing.List(tables.Categories.Rows(), params, categoryFields)
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pointers(page), total, nil
+}
+
+// GetElseCreate find category by name and create it in one write, so concurrent calls don't create duplicates.
+func (c *memoryCategoryRepository) GetElseCreate(ctx context.Context, category *domain.Category) (*domain.Category, error) {
+	var result domain.Category
+	err := c.DB.Write(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if result, ok = findByName(tables.Categories, category.Name, categoryName); ok {
+			return nil
+		}
+
+		category.Id = tables.Categories.NextId()
+		tables.Categories.Put(category.Id, *category)
+		result = *category
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// tagFields are fields of tag available for sorting and filtering in memory, like tagColumns
+var tagFields = listing.Fields[domain.Tag]{
+	"id":   {Kind: listing.KindInt, Value: func(t domain.Tag) interface{} { return t.Id }},
+	"name": {Kind: listing.KindString, Value: func(t domain.Tag) interface{} { return t.Name }},
+}
+
+type memoryTagRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryTagRepository(db *memory.DB) domain.TagRepository {
+	return &memoryTagRepository{DB: db}
+}
+
+func (t *memoryTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	return t.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := findByName(tables.Tags, tag.Name, tagName); ok {
+			return fmt.Errorf("tag %s already exists", tag.Name)
+		}
+
+		tag.Id = tables.Tags.NextId()
+		tables.Tags.Put(tag.Id, *tag)
+
+		return nil
+	})
+}
+
+func (t *memoryTagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := t.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if tag, ok = findByName(tables.Tags, name, tagName); !ok {
+			return domain.ErrTagNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (t *memoryTagRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Tag, int, error) {
+	var page []domain.Tag
+	var total int
+	err := t.DB.Read(ctx, func(tables *memory.Tables) error {
+		var err error
+		page, total, err = listing.List(tables.Tags.Rows(), params, tagFields)
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pointers(page), total, nil
+}
+
+// GetElseCreate find tag by name and create it in one write, so concurrent calls don't create duplicates.
+func (t *memoryTagRepository) GetElseCreate(ctx context.Context, tag *domain.Tag) (*domain.Tag, error) {
+	var result domain.Tag
+	err := t.DB.Write(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if result, ok = findByName(tables.Tags, tag.Name, tagName); ok {
+			return nil
+		}
+
+		tag.Id = tables.Tags.NextId()
+		tables.Tags.Put(tag.Id, *tag)
+		result = *tag
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (t *memoryTagRepository) AddTagsToPet(ctx context.Context, petId int, tagIds []int) error {
+	return t.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Pets.Get(petId); !ok && len(tagIds) > 0 {
+			return domain.ErrPetNotFound
+		}
+
+		for _, tagId := range tagIds {
+			if _, ok := tables.Tags.Get(tagId); !ok {
+				return domain.ErrTagNotFound
+			}
+		}
+
+		for _, tagId := range tagIds {
+			tables.PetTags.Put(tables.PetTags.NextId(), memory.PetTag{PetId: petId, TagId: tagId})
+		}
+
+		return nil
+	})
+}
+
+func (t *memoryTagRepository) GetPetTags(ctx context.Context, petId int) ([]*domain.Tag, error) {
+	petsTags, err := t.GetPetsTags(ctx, []int{petId})
+	if err != nil {
+		return nil, err
+	}
+
+	if petsTags[petId] == nil {
+		return make([]*domain.Tag, 0), nil
+	}
+
+	return petsTags[petId], nil
+}
+
+func (t *memoryTagRepository) GetPetsTags(ctx context.Context, petIds []int) (map[int][]*domain.Tag, error) {
+	ids := make(map[int]bool, len(petIds))
+	for _, petId := range petIds {
+		ids[petId] = true
+	}
+
+	tags := make(map[int][]*domain.Tag, len(petIds))
+	err := t.DB.Read(ctx, func(tables *memory.Tables) error {
+		for _, petTag := range tables.PetTags.Rows() {
+			if !ids[petTag.PetId] {
+				continue
+			}
+
+			if tag, ok := tables.Tags.Get(petTag.TagId); ok {
+				tags[petTag.PetId] = append(tags[petTag.PetId], &tag)
+			}
+		}
+
+		return nil
+	})
+
+	return tags, err
+}
+
+func (t *memoryTagRepository) RemovePetTags(ctx context.Context, petId int) error {
+	return t.DB.Write(ctx, func(tables *memory.Tables) error {
+		tables.PetTags.DeleteWhere(func(petTag memory.PetTag) bool {
+			return petTag.PetId == petId
+		})
+
+		return nil
+	})
+}
+
+type memoryPhotoRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryPhotoRepository(db *memory.DB) domain.PhotoRepository {
+	return &memoryPhotoRepository{DB: db}
+}
+
+func (p *memoryPhotoRepository) Create(ctx context.Context, photo *domain.PhotoDTO) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Pets.Get(photo.PetId); !ok {
+			return domain.ErrPetNotFound
+		}
+
+		photo.Id = tables.Photos.NextId()
+		tables.Photos.Put(photo.Id, *photo)
+
+		return nil
+	})
+}
+
+func (p *memoryPhotoRepository) Delete(ctx context.Context, id int) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		tables.Photos.Delete(id)
+		return nil
+	})
+}
+
+func (p *memoryPhotoRepository) GetByPet(ctx context.Context, petId int) ([]*domain.PhotoDTO, error) {
+	petsPhotos, err := p.GetByPets(ctx, []int{petId})
+	if err != nil {
+		return nil, err
+	}
+
+	if petsPhotos[petId] == nil {
+		return make([]*domain.PhotoDTO, 0), nil
+	}
+
+	return petsPhotos[petId], nil
+}
+
+func (p *memoryPhotoRepository) GetByPets(ctx context.Context, petIds []int) (map[int][]*domain.PhotoDTO, error) {
+	ids := make(map[int]bool, len(petIds))
+	for _, petId := range petIds {
+		ids[petId] = true
+	}
+
+	photos := make(map[int][]*domain.PhotoDTO, len(petIds))
+	err := p.DB.Read(ctx, func(tables *memory.Tables) error {
+		for _, photo := range tables.Photos.Rows() {
+			if ids[photo.PetId] {
+				photo := photo
+				photos[photo.PetId] = append(photos[photo.PetId], &photo)
+			}
+		}
+
+		return nil
+	})
+
+	return photos, err
+}
+
+func (p *memoryPhotoRepository) DeleteByPet(ctx context.Context, petId int) error {
+	return p.DB.Write(ctx, func(tables *memory.Tables) error {
+		tables.Photos.DeleteWhere(func(photo domain.PhotoDTO) bool {
+			return photo.PetId == petId
+		})
+
+		return nil
+	})
+}
+
+func categoryName(category domain.Category) string {
+	return category.Name
+}
+
+func tagName(tag domain.Tag) string {
+	return tag.Name
+}
+
+// findByName - find row by unique name.
+func findByName[T any](table *memory.Table[T], name string, nameOf func(row T) string) (T, bool) {
+	return table.Find(func(row T) bool {
+		return nameOf(row) == name
+	})
+}
+
+// pointers returns pointers to copies of items.
+func pointers[T any](items []T) []*T {
+	result := make([]*T, 0, len(items))
+	for i := range items {
+		result = append(result, &items[i])
+	}
+
+	return result
+}
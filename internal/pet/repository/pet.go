@@ -6,6 +6,8 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/transaction"
 )
 
 type petRepository struct {
@@ -13,34 +15,161 @@ type petRepository struct {
 	SqlBuilder sq.StatementBuilderType
 }
 
-func (p *petRepository) GetByStatus(ctx context.Context, status domain.PetStatus) ([]*domain.PetDTO, error) {
-	query := p.SqlBuilder.Select("id", "category_id", "name", "status")
-	query = query.From("pets").Where(sq.Eq{"status": status})
+// petStatuses are values of PetStatus enum type
+var petStatuses = listing.EnumValues(domain.PetStatuses)
+
+// petColumns are fields of pet available for sorting and filtering
+var petColumns = listing.Columns{
+	"id":         {Name: "pets.id", Kind: listing.KindInt},
+	"name":       {Name: "pets.name", Kind: listing.KindString},
+	"status":     {Name: "pets.status", Kind: listing.KindEnum, Values: petStatuses},
+	"categoryId": {Name: "pets.category_id", Kind: listing.KindInt},
+}
+
+func (p *petRepository) GetByStatus(ctx context.Context, status domain.PetStatus,
+	params *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	query := p.SqlBuilder.Select("pets.id", "pets.category_id", "pets.name", "pets.status")
+	query = query.From("pets").Where(sq.Eq{"pets.status": status})
+
+	return p.list(ctx, query, params)
+}
+
+func (p *petRepository) GetByTags(ctx context.Context, tags []string, match domain.TagMatch,
+	params *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	query := p.SqlBuilder.Select("pets.id", "pets.category_id", "pets.name", "pets.status").From("pets")
+	query = query.Join("pets_tags ON pets_tags.pet_id = pets.id")
+	query = query.Join("tags ON tags.id = pets_tags.tag_id")
+	query = query.Where(sq.Eq{"tags.name": tags}).GroupBy("pets.id")
+
+	if match == domain.TagMatchAll {
+		query = query.Having("COUNT(DISTINCT tags.id) = ?", countUnique(tags))
+	}
+
+	return p.list(ctx, query, params)
+}
+
+// list - apply list params to query of pets and run it.
+// Returns page of pets and total number of pets.
+func (p *petRepository) list(ctx context.Context, query sq.SelectBuilder,
+	params *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	return listing.Query(ctx, transaction.Conn(ctx, p.Conn), query, params, petColumns, "pets.id",
+		func(row sq.RowScanner) (*domain.PetDTO, error) {
+			var pet domain.PetDTO
+			err := row.Scan(&pet.Id, &pet.CategoryId, &pet.Name, &pet.Status)
+
+			return &pet, err
+		})
+}
+
+func (p *petRepository) UpdateStatusFrom(ctx context.Context, id int, from domain.PetStatus,
+	to domain.PetStatus) (bool, error) {
+	query := p.SqlBuilder.Update("pets").Set("status", to)
+	query = query.Where(sq.Eq{"id": id, "status": from})
+
+	res, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	isUpdate, err := res.RowsAffected()
 
-	rows, err := query.RunWith(p.Conn).QueryContext(ctx)
+	return isUpdate > 0, err
+}
+
+func (p *petRepository) UpdateStatus(ctx context.Context, id int, status domain.PetStatus) error {
+	query := p.SqlBuilder.Update("pets").Set("status", status).Where(sq.Eq{"id": id})
+
+	res, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	isUpdate, _ := res.RowsAffected()
+	if isUpdate == 0 {
+		return domain.ErrPetNotFound
+	}
+
+	return nil
+}
+
+func (p *petRepository) CountByStatus(ctx context.Context) (map[domain.PetStatus]int, error) {
+	query := p.SqlBuilder.Select("status", "COUNT(*)").From("pets")
+	query = query.Where(sq.NotEq{"status": nil}).GroupBy("status")
+
+	rows, err := transaction.Conn(ctx, p.Conn).QueryRows(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	pets := make([]*domain.PetDTO, 0)
+	counts := make(map[domain.PetStatus]int)
 	for rows.Next() {
-		var pet domain.PetDTO
-		if err := rows.Scan(&pet.Id, &pet.CategoryId, &pet.Name, &pet.Status); err != nil {
+		var status domain.PetStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
 			return nil, err
 		}
 
-		pets = append(pets, &pet)
+		counts[status] = count
 	}
 
-	return pets, err
+	return counts, rows.Err()
+}
+
+func (p *petRepository) CountByCategoryAndStatus(ctx context.Context) (map[string]map[domain.PetStatus]int, error) {
+	query := p.SqlBuilder.Select("categories.name", "pets.status", "COUNT(*)").From("pets")
+	query = query.Join("categories ON categories.id = pets.category_id")
+	query = query.Where(sq.NotEq{"pets.status": nil}).GroupBy("categories.name", "pets.status")
+
+	rows, err := transaction.Conn(ctx, p.Conn).QueryRows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[domain.PetStatus]int)
+	for rows.Next() {
+		var category string
+		var status domain.PetStatus
+		var count int
+		if err := rows.Scan(&category, &status, &count); err != nil {
+			return nil, err
+		}
+
+		if counts[category] == nil {
+			counts[category] = make(map[domain.PetStatus]int)
+		}
+		counts[category][status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func countUnique(values []string) int {
+	unique := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		unique[value] = struct{}{}
+	}
+
+	return len(unique)
 }
 
 func (p *petRepository) Get(ctx context.Context, id int) (*domain.PetDTO, error) {
 	query := p.SqlBuilder.Select("id", "category_id", "name", "status")
 	query = query.From("pets").Where(sq.Eq{"id": id})
 
-	row := query.RunWith(p.Conn).QueryRowContext(ctx)
+	return p.get(ctx, query)
+}
+
+func (p *petRepository) GetForUpdate(ctx context.Context, id int) (*domain.PetDTO, error) {
+	query := p.SqlBuilder.Select("id", "category_id", "name", "status")
+	query = query.From("pets").Where(sq.Eq{"id": id}).Suffix("FOR UPDATE")
+
+	return p.get(ctx, query)
+}
+
+func (p *petRepository) get(ctx context.Context, query sq.SelectBuilder) (*domain.PetDTO, error) {
+	row := query.RunWith(transaction.Conn(ctx, p.Conn)).QueryRowContext(ctx)
 	var pet domain.PetDTO
 	err := row.Scan(&pet.Id, &pet.CategoryId, &pet.Name, &pet.Status)
 
@@ -56,26 +185,38 @@ func (p *petRepository) Update(ctx context.Context, pet *domain.PetDTO) error {
 	query = query.Set("category_id", pet.CategoryId).Set("name", pet.Name).Set("status", pet.Status)
 	query = query.Where(sq.Eq{"id": pet.Id})
 
-	res, err := query.RunWith(p.Conn).ExecContext(ctx)
+	res, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
+	if transaction.IsForeignKeyViolation(err, "pets_category_id_fkey") {
+		return domain.ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
 
 	isUpdate, _ := res.RowsAffected()
 	if isUpdate == 0 {
 		return domain.ErrPetNotFound
 	}
 
-	return err
+	return nil
 }
 
 func (p *petRepository) Delete(ctx context.Context, id int) error {
 	query := p.SqlBuilder.Delete("pets").Where(sq.Eq{"id": id})
-	res, err := query.RunWith(p.Conn).ExecContext(ctx)
+	res, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
+	if transaction.IsForeignKeyViolation(err, "orders_pet_id_fkey") {
+		return domain.ErrPetHasOrders
+	}
+	if err != nil {
+		return err
+	}
 
 	isDelete, _ := res.RowsAffected()
 	if isDelete == 0 {
 		return domain.ErrPetNotFound
 	}
 
-	return err
+	return nil
 }
 
 func (p *petRepository) Create(ctx context.Context, pet *domain.PetDTO) error {
@@ -83,9 +224,13 @@ func (p *petRepository) Create(ctx context.Context, pet *domain.PetDTO) error {
 	query = query.Values(pet.CategoryId, pet.Name, pet.Status)
 	query = query.Suffix("RETURNING id")
 
-	row := query.RunWith(p.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, p.Conn)).QueryRowContext(ctx)
 	var id int
-	if err := row.Scan(&id); err != nil {
+	err := row.Scan(&id)
+	if transaction.IsForeignKeyViolation(err, "pets_category_id_fkey") {
+		return domain.ErrCategoryNotFound
+	}
+	if err != nil {
 		return err
 	}
 
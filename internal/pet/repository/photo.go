@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/transaction"
 )
 
 type photoRepository struct {
@@ -14,18 +15,29 @@ type photoRepository struct {
 
 func (p *photoRepository) DeleteByPet(ctx context.Context, petId int) error {
 	query := p.SqlBuilder.Delete("photos").Where(sq.Eq{"pet_id": petId})
-	_, err := query.RunWith(p.Conn).ExecContext(ctx)
+	_, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
 
 	return err
 }
 
-func (p *photoRepository) Create(ctx context.Context, photo domain.PhotoDTO) error {
+func (p *photoRepository) Delete(ctx context.Context, id int) error {
+	query := p.SqlBuilder.Delete("photos").Where(sq.Eq{"id": id})
+	_, err := query.RunWith(transaction.Conn(ctx, p.Conn)).ExecContext(ctx)
+
+	return err
+}
+
+func (p *photoRepository) Create(ctx context.Context, photo *domain.PhotoDTO) error {
 	query := p.SqlBuilder.Insert("photos").Columns("pet_id").Values(photo.PetId)
 	query = query.Suffix("RETURNING id")
 
-	row := query.RunWith(p.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, p.Conn)).QueryRowContext(ctx)
 	var id int
-	if err := row.Scan(&id); err != nil {
+	err := row.Scan(&id)
+	if transaction.IsForeignKeyViolation(err, "photos_pet_id_fkey") {
+		return domain.ErrPetNotFound
+	}
+	if err != nil {
 		return err
 	}
 
@@ -37,7 +49,7 @@ func (p *photoRepository) GetByPet(ctx context.Context, petId int) ([]*domain.Ph
 	query := p.SqlBuilder.Select("id", "pet_id").From("photos")
 	query = query.Where(sq.Eq{"pet_id": petId})
 
-	rows, err := query.RunWith(p.Conn).QueryContext(ctx)
+	rows, err := transaction.Conn(ctx, p.Conn).QueryRows(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +69,33 @@ func (p *photoRepository) GetByPet(ctx context.Context, petId int) ([]*domain.Ph
 	return photos, nil
 }
 
+func (p *photoRepository) GetByPets(ctx context.Context, petIds []int) (map[int][]*domain.PhotoDTO, error) {
+	photos := make(map[int][]*domain.PhotoDTO, len(petIds))
+	if len(petIds) == 0 {
+		return photos, nil
+	}
+
+	query := p.SqlBuilder.Select("id", "pet_id").From("photos")
+	query = query.Where(sq.Eq{"pet_id": petIds}).OrderBy("id")
+
+	rows, err := transaction.Conn(ctx, p.Conn).QueryRows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo domain.PhotoDTO
+		if err := rows.Scan(&photo.Id, &photo.PetId); err != nil {
+			return nil, err
+		}
+
+		photos[photo.PetId] = append(photos[photo.PetId], &photo)
+	}
+
+	return photos, rows.Err()
+}
+
 func NewPhotoRepository(conn *sql.DB) domain.PhotoRepository {
 	return &photoRepository{Conn: conn, SqlBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar)}
 }
@@ -6,6 +6,8 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/transaction"
 )
 
 type tagRepository struct {
@@ -14,15 +16,25 @@ type tagRepository struct {
 }
 
 func (t *tagRepository) AddTagsToPet(ctx context.Context, petId int, tagIds []int) error {
+	if len(tagIds) == 0 {
+		return nil
+	}
+
 	query := t.SqlBuilder.Insert("pets_tags").Columns("pet_id", "tag_id")
 
 	for _, tagId := range tagIds {
 		query = query.Values(petId, tagId)
 	}
 
-	_, err := query.RunWith(t.Conn).ExecContext(ctx)
-
-	return err
+	_, err := query.RunWith(transaction.Conn(ctx, t.Conn)).ExecContext(ctx)
+	switch {
+	case transaction.IsForeignKeyViolation(err, "pets_tags_pet_id_fkey"):
+		return domain.ErrPetNotFound
+	case transaction.IsForeignKeyViolation(err, "pets_tags_tag_id_fkey"):
+		return domain.ErrTagNotFound
+	default:
+		return err
+	}
 }
 
 func (t *tagRepository) GetPetTags(ctx context.Context, petId int) ([]*domain.Tag, error) {
@@ -30,7 +42,7 @@ func (t *tagRepository) GetPetTags(ctx context.Context, petId int) ([]*domain.Ta
 	query = query.Join("tags ON tags.id = pets_tags.tag_id")
 	query = query.Where(sq.Eq{"pets_tags.pet_id": petId})
 
-	rows, err := query.RunWith(t.Conn).QueryContext(ctx)
+	rows, err := transaction.Conn(ctx, t.Conn).QueryRows(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -50,9 +62,38 @@ func (t *tagRepository) GetPetTags(ctx context.Context, petId int) ([]*domain.Ta
 	return tags, nil
 }
 
+func (t *tagRepository) GetPetsTags(ctx context.Context, petIds []int) (map[int][]*domain.Tag, error) {
+	tags := make(map[int][]*domain.Tag, len(petIds))
+	if len(petIds) == 0 {
+		return tags, nil
+	}
+
+	query := t.SqlBuilder.Select("pets_tags.pet_id", "tags.id", "tags.name").From("pets_tags")
+	query = query.Join("tags ON tags.id = pets_tags.tag_id")
+	query = query.Where(sq.Eq{"pets_tags.pet_id": petIds})
+
+	rows, err := transaction.Conn(ctx, t.Conn).QueryRows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var petId int
+		var tag domain.Tag
+		if err := rows.Scan(&petId, &tag.Id, &tag.Name); err != nil {
+			return nil, err
+		}
+
+		tags[petId] = append(tags[petId], &tag)
+	}
+
+	return tags, rows.Err()
+}
+
 func (t *tagRepository) RemovePetTags(ctx context.Context, petId int) error {
 	query := t.SqlBuilder.Delete("pets_tags").Where(sq.Eq{"pet_id": petId})
-	_, err := query.RunWith(t.Conn).ExecContext(ctx)
+	_, err := query.RunWith(transaction.Conn(ctx, t.Conn)).ExecContext(ctx)
 
 	return err
 }
@@ -79,7 +120,7 @@ func (t *tagRepository) GetElseCreate(ctx context.Context, tag *domain.Tag) (*do
 
 func (t *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
 	query := t.SqlBuilder.Select("id", "name").From("tags").Where(sq.Eq{"name": name})
-	row := query.RunWith(t.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, t.Conn)).QueryRowContext(ctx)
 
 	var tag domain.Tag
 	err := row.Scan(&tag.Id, &tag.Name)
@@ -94,7 +135,7 @@ func (t *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag
 func (t *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
 	query := t.SqlBuilder.Insert("tags").Columns("name").Values(tag.Name)
 	query = query.Suffix("RETURNING id")
-	row := query.RunWith(t.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, t.Conn)).QueryRowContext(ctx)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
@@ -109,3 +150,21 @@ func (t *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
 func NewTagRepository(conn *sql.DB) domain.TagRepository {
 	return &tagRepository{Conn: conn, SqlBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar)}
 }
+
+// tagColumns are fields of tag available for sorting and filtering
+var tagColumns = listing.Columns{
+	"id":   {Name: "id", Kind: listing.KindInt},
+	"name": {Name: "name", Kind: listing.KindString},
+}
+
+func (t *tagRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.Tag, int, error) {
+	query := t.SqlBuilder.Select("id", "name").From("tags")
+
+	return listing.Query(ctx, transaction.Conn(ctx, t.Conn), query, params, tagColumns, "id",
+		func(row sq.RowScanner) (*domain.Tag, error) {
+			var item domain.Tag
+			err := row.Scan(&item.Id, &item.Name)
+
+			return &item, err
+		})
+}
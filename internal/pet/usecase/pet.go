@@ -1,123 +1,316 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
 	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"petstore/internal/tracing"
 )
 
 type petUsecase struct {
 	petRepo      domain.PetRepository
 	categoryRepo domain.CategoryRepository
 	tagRepo      domain.TagRepository
+	photoRepo    domain.PhotoRepository
+	orderRepo    domain.OrderRepository
+	blobStore    domain.BlobStore
+
+	imageProcessor domain.ImageProcessor
+	txManager      domain.TxManager
+}
+
+func (p *petUsecase) UploadImage(ctx context.Context, photo *domain.PhotoDTO, image io.Reader) (err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.UploadImage")
+	defer tracing.End(span, &err)
+
+	if _, err := p.petRepo.Get(ctx, photo.PetId); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return err
+	}
+
+	processed, err := p.imageProcessor.Process(data)
+	if err != nil {
+		return err
+	}
+
+	if err := p.photoRepo.Create(ctx, photo); err != nil {
+		return err
+	}
+
+	if saveErr := p.savePhotoFiles(ctx, photo, processed); saveErr != nil {
+		logging.FromContext(ctx).Warn("failed to save photo files, photo is removed",
+			zap.Int("petId", photo.PetId), zap.Int("photoId", photo.Id), zap.Error(saveErr))
+
+		// files are not saved, so photo must not be visible even if some files can not be removed
+		removeErr := p.removePhotoFiles(ctx, photo)
+		deleteErr := p.photoRepo.Delete(ctx, photo.Id)
+
+		return errors.Join(saveErr, removeErr, deleteErr)
+	}
+
+	return nil
+}
+
+// savePhotoFiles - save original image and all thumbnails.
+func (p *petUsecase) savePhotoFiles(ctx context.Context, photo *domain.PhotoDTO, processed *domain.ProcessedImage) error {
+	err := p.blobStore.Put(ctx, photo.GetPath(), bytes.NewReader(processed.Original),
+		int64(len(processed.Original)), "image/jpeg")
+	if err != nil {
+		return err
+	}
+
+	for name, data := range processed.Thumbnails {
+		err := p.blobStore.Put(ctx, photo.GetThumbnailPath(name), bytes.NewReader(data), int64(len(data)), "image/jpeg")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removePhotoFiles - remove original image and all thumbnails.
+// Each file is removed even if removing of previous one failed, errors are joined.
+func (p *petUsecase) removePhotoFiles(ctx context.Context, photo *domain.PhotoDTO) error {
+	errs := []error{p.blobStore.Delete(ctx, photo.GetPath())}
+	for _, thumbnail := range p.imageProcessor.Thumbnails() {
+		errs = append(errs, p.blobStore.Delete(ctx, photo.GetThumbnailPath(thumbnail.Name)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *petUsecase) GetByStatus(ctx context.Context, status domain.PetStatus,
+	params *domain.ListParams) (pets []*domain.Pet, total int, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.GetByStatus")
+	defer tracing.End(span, &err)
+
+	petsDTO, total, err := p.petRepo.GetByStatus(ctx, status, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pets, err = p.assemblePets(ctx, petsDTO)
+	return pets, total, err
+}
+
+func (p *petUsecase) GetByTags(ctx context.Context, tags []string, match domain.TagMatch,
+	params *domain.ListParams) (pets []*domain.Pet, total int, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.GetByTags")
+	defer tracing.End(span, &err)
+
+	petsDTO, total, err := p.petRepo.GetByTags(ctx, tags, match, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pets, err = p.assemblePets(ctx, petsDTO)
+	return pets, total, err
+}
+
+func (p *petUsecase) ListCategories(ctx context.Context,
+	params *domain.ListParams) (categories []*domain.Category, total int, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.ListCategories")
+	defer tracing.End(span, &err)
+
+	return p.categoryRepo.List(ctx, params)
 }
 
-func (p *petUsecase) UploadImage(ctx context.Context, photo *domain.PhotoDTO) error {
-	//TODO implement me
-	panic("implement me")
+func (p *petUsecase) ListTags(ctx context.Context,
+	params *domain.ListParams) (tags []*domain.Tag, total int, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.ListTags")
+	defer tracing.End(span, &err)
+
+	return p.tagRepo.List(ctx, params)
 }
 
-func (p *petUsecase) GetByStatus(ctx context.Context, status domain.PetStatus) ([]*domain.Pet, error) {
-	petsDTO, err := p.petRepo.GetByStatus(ctx, status)
+// assemblePets - load categories, tags and photos of pets.
+// Number of queries does not depend on number of pets.
+func (p *petUsecase) assemblePets(ctx context.Context, petsDTO []*domain.PetDTO) (pets []*domain.Pet, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.assemblePets")
+	defer tracing.End(span, &err)
+
+	petIds := make([]int, 0, len(petsDTO))
+	categoryIds := make([]int, 0, len(petsDTO))
+	for _, petDTO := range petsDTO {
+		petIds = append(petIds, petDTO.Id)
+		categoryIds = append(categoryIds, petDTO.CategoryId)
+	}
+
+	categories, err := p.categoryRepo.GetByIds(ctx, categoryIds)
 	if err != nil {
 		return nil, err
 	}
 
-	pets := make([]*domain.Pet, 0, len(petsDTO))
+	tags, err := p.tagRepo.GetPetsTags(ctx, petIds)
+	if err != nil {
+		return nil, err
+	}
 
+	photos, err := p.photoRepo.GetByPets(ctx, petIds)
+	if err != nil {
+		return nil, err
+	}
+
+	pets = make([]*domain.Pet, 0, len(petsDTO))
 	for _, petDTO := range petsDTO {
-		category, err := p.categoryRepo.Get(ctx, petDTO.CategoryId)
-		if err != nil {
-			return nil, err
+		// category of pet is required by foreign key, so missing category is internal error, not 404
+		category, ok := categories[petDTO.CategoryId]
+		if !ok {
+			return nil, fmt.Errorf("category %d of pet %d is not found", petDTO.CategoryId, petDTO.Id)
 		}
 
-		tags, err := p.tagRepo.GetPetTags(ctx, petDTO.Id)
-		if err != nil {
-			return nil, err
+		petTags := tags[petDTO.Id]
+		if petTags == nil {
+			petTags = make([]*domain.Tag, 0)
 		}
 
-		pet := domain.PetDTOToPet(petDTO, category, tags)
+		pet := domain.PetDTOToPet(petDTO, category, petTags, photos[petDTO.Id], p.imageProcessor.Thumbnails())
 		pets = append(pets, pet)
 	}
 
 	return pets, nil
 }
 
-func (p *petUsecase) Get(ctx context.Context, id int) (*domain.Pet, error) {
+func (p *petUsecase) Get(ctx context.Context, id int) (pet *domain.Pet, err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.Get")
+	defer tracing.End(span, &err)
+
 	petDTO, err := p.petRepo.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	category, err := p.categoryRepo.Get(ctx, petDTO.CategoryId)
+	pets, err := p.assemblePets(ctx, []*domain.PetDTO{petDTO})
 	if err != nil {
 		return nil, err
 	}
 
-	tags, err := p.tagRepo.GetPetTags(ctx, id)
-
-	pet := domain.PetDTOToPet(petDTO, category, tags)
-	return pet, nil
+	return pets[0], nil
 }
 
-func (p *petUsecase) Update(ctx context.Context, pet *domain.Pet) error {
-	petDTO := domain.PetToPetDTO(pet)
+func (p *petUsecase) Update(ctx context.Context, pet *domain.Pet) (err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.Update")
+	defer tracing.End(span, &err)
 
-	category, err := p.categoryRepo.GetElseCreate(ctx, pet.Category)
-	if err != nil {
-		return err
-	}
+	return p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// pet is locked, so order can not reserve it until status is updated
+		current, err := p.petRepo.GetForUpdate(ctx, pet.Id)
+		if err != nil {
+			return err
+		}
 
-	petDTO.CategoryId = category.Id
-	if err := p.tagRepo.RemovePetTags(ctx, pet.Id); err != nil {
-		return err
-	}
+		// status of reserved pet is changed only by its order, else pet can be ordered twice
+		if current.Status != pet.Status {
+			active, err := p.orderRepo.HasActiveByPet(ctx, pet.Id)
+			if err != nil {
+				return err
+			}
 
-	tagsIds := make([]int, 0, len(pet.Tags))
-	for _, tag := range pet.Tags {
-		tagDb, err := p.tagRepo.GetElseCreate(ctx, tag)
+			if active {
+				return domain.ErrPetHasActiveOrder
+			}
+		}
+
+		petDTO := domain.PetToPetDTO(pet)
+
+		category, err := p.categoryRepo.GetElseCreate(ctx, pet.Category)
 		if err != nil {
 			return err
 		}
 
-		tagsIds = append(tagsIds, tagDb.Id)
-	}
+		petDTO.CategoryId = category.Id
+		if err := p.petRepo.Update(ctx, petDTO); err != nil {
+			return err
+		}
 
-	if err := p.tagRepo.AddTagsToPet(ctx, petDTO.Id, tagsIds); err != nil {
-		return err
-	}
+		if err := p.tagRepo.RemovePetTags(ctx, pet.Id); err != nil {
+			return err
+		}
 
-	return p.petRepo.Update(ctx, petDTO)
+		return p.addTags(ctx, petDTO.Id, pet.Tags)
+	})
 }
 
-func (p *petUsecase) Delete(ctx context.Context, id int) error {
-	if err := p.tagRepo.RemovePetTags(ctx, id); err != nil {
-		return err
-	}
+func (p *petUsecase) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.Delete")
+	defer tracing.End(span, &err)
 
-	return p.petRepo.Delete(ctx, id)
-}
+	var photos []*domain.PhotoDTO
+
+	err = p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := p.tagRepo.RemovePetTags(ctx, id); err != nil {
+			return err
+		}
 
-func (p *petUsecase) Create(ctx context.Context, pet *domain.Pet) error {
-	category, err := p.categoryRepo.GetElseCreate(ctx, pet.Category)
+		var err error
+		photos, err = p.photoRepo.GetByPet(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := p.photoRepo.DeleteByPet(ctx, id); err != nil {
+			return err
+		}
+
+		return p.petRepo.Delete(ctx, id)
+	})
 	if err != nil {
 		return err
 	}
 
-	petDTO := &domain.PetDTO{
-		CategoryId: category.Id,
-		Name:       pet.Name,
-		Status:     pet.Status,
+	// files are removed only after commit, so rolled back pet keeps its photos.
+	// Pet is already deleted, so left files are only logged and do not fail request.
+	for _, photo := range photos {
+		if err := p.removePhotoFiles(ctx, photo); err != nil {
+			logging.FromContext(ctx).Error("pet is deleted, but its photo files are not removed",
+				zap.Int("petId", id), zap.Int("photoId", photo.Id), zap.Error(err))
+		}
 	}
 
-	err = p.petRepo.Create(ctx, petDTO)
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	pet.Id = petDTO.Id
+func (p *petUsecase) Create(ctx context.Context, pet *domain.Pet) (err error) {
+	ctx, span := tracing.Start(ctx, "petUsecase.Create")
+	defer tracing.End(span, &err)
 
-	tagsIds := make([]int, 0, len(pet.Tags))
-	for _, tag := range pet.Tags {
+	return p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		category, err := p.categoryRepo.GetElseCreate(ctx, pet.Category)
+		if err != nil {
+			return err
+		}
+
+		petDTO := &domain.PetDTO{
+			CategoryId: category.Id,
+			Name:       pet.Name,
+			Status:     pet.Status,
+		}
+
+		err = p.petRepo.Create(ctx, petDTO)
+		if err != nil {
+			return err
+		}
+
+		pet.Id = petDTO.Id
+
+		return p.addTags(ctx, petDTO.Id, pet.Tags)
+	})
+}
+
+// addTags - add tags to pet, tags which do not exist are created.
+func (p *petUsecase) addTags(ctx context.Context, petId int, tags []*domain.Tag) error {
+	tagsIds := make([]int, 0, len(tags))
+	for _, tag := range tags {
 		tagDb, err := p.tagRepo.GetElseCreate(ctx, tag)
 		if err != nil {
 			return err
@@ -126,17 +319,21 @@ func (p *petUsecase) Create(ctx context.Context, pet *domain.Pet) error {
 		tagsIds = append(tagsIds, tagDb.Id)
 	}
 
-	if err := p.tagRepo.AddTagsToPet(ctx, petDTO.Id, tagsIds); err != nil {
-		return err
-	}
-
-	return nil
+	return p.tagRepo.AddTagsToPet(ctx, petId, tagsIds)
 }
 
-func NewPetUsecase(pr domain.PetRepository, cr domain.CategoryRepository, tr domain.TagRepository) domain.PetUsecase {
+func NewPetUsecase(pr domain.PetRepository, cr domain.CategoryRepository, tr domain.TagRepository,
+	phr domain.PhotoRepository, or domain.OrderRepository, bs domain.BlobStore, ip domain.ImageProcessor,
+	tm domain.TxManager) domain.PetUsecase {
 	return &petUsecase{
 		petRepo:      pr,
 		categoryRepo: cr,
 		tagRepo:      tr,
+		photoRepo:    phr,
+		orderRepo:    or,
+		blobStore:    bs,
+
+		imageProcessor: ip,
+		txManager:      tm,
 	}
 }
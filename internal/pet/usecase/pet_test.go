@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"petstore/internal/memory"
+	_orderRepository "petstore/internal/order/repository"
+	"petstore/internal/pet/repository"
+	"testing"
+)
+
+// calls counts calls of repositories.
+type calls struct {
+	count int
+}
+
+// countingPetRepo returns pets pets of one category, other methods are not implemented.
+type countingPetRepo struct {
+	domain.PetRepository
+	*calls
+	pets int
+}
+
+func (r *countingPetRepo) GetByStatus(_ context.Context, status domain.PetStatus,
+	_ *domain.ListParams) ([]*domain.PetDTO, int, error) {
+	r.count++
+
+	pets := make([]*domain.PetDTO, 0, r.pets)
+	for i := 1; i <= r.pets; i++ {
+		pets = append(pets, &domain.PetDTO{Id: i, CategoryId: 1, Name: fmt.Sprintf("pet %d", i), Status: status})
+	}
+
+	return pets, r.pets, nil
+}
+
+type countingCategoryRepo struct {
+	domain.CategoryRepository
+	*calls
+}
+
+func (r *countingCategoryRepo) GetByIds(_ context.Context, ids []int) (map[int]*domain.Category, error) {
+	r.count++
+
+	categories := make(map[int]*domain.Category, len(ids))
+	for _, id := range ids {
+		categories[id] = &domain.Category{Id: id, Name: "dogs"}
+	}
+
+	return categories, nil
+}
+
+type countingTagRepo struct {
+	domain.TagRepository
+	*calls
+}
+
+func (r *countingTagRepo) GetPetsTags(_ context.Context, petIds []int) (map[int][]*domain.Tag, error) {
+	r.count++
+
+	tags := make(map[int][]*domain.Tag, len(petIds))
+	for _, id := range petIds {
+		tags[id] = []*domain.Tag{{Id: 1, Name: "friendly"}}
+	}
+
+	return tags, nil
+}
+
+type countingPhotoRepo struct {
+	domain.PhotoRepository
+	*calls
+}
+
+func (r *countingPhotoRepo) GetByPets(_ context.Context, petIds []int) (map[int][]*domain.PhotoDTO, error) {
+	r.count++
+
+	photos := make(map[int][]*domain.PhotoDTO, len(petIds))
+	for _, id := range petIds {
+		photos[id] = []*domain.PhotoDTO{{Id: id, PetId: id}}
+	}
+
+	return photos, nil
+}
+
+type stubImageProcessor struct {
+	domain.ImageProcessor
+}
+
+func (stubImageProcessor) Thumbnails() []domain.ThumbnailSize {
+	return []domain.ThumbnailSize{{Name: "small", Size: 100}}
+}
+
+// newCountingUsecase create usecase, which lists pets pets, and counter of its repositories calls.
+func newCountingUsecase(pets int) (domain.PetUsecase, *calls) {
+	c := &calls{}
+	usecase := NewPetUsecase(&countingPetRepo{calls: c, pets: pets}, &countingCategoryRepo{calls: c},
+		&countingTagRepo{calls: c}, &countingPhotoRepo{calls: c}, nil, nil, stubImageProcessor{}, nil)
+
+	return usecase, c
+}
+
+func TestGetByStatusCallsDoNotDependOnPets(t *testing.T) {
+	ctx := context.Background()
+	params := &domain.ListParams{Page: 1, Limit: 500}
+
+	counts := make(map[int]int)
+	for _, pets := range []int{1, 500} {
+		usecase, c := newCountingUsecase(pets)
+
+		result, _, err := usecase.GetByStatus(ctx, domain.PetStatusAvailable, params)
+		if err != nil {
+			t.Fatalf("%d pets: %v", pets, err)
+		}
+
+		if len(result) != pets {
+			t.Fatalf("got %d pets, want %d", len(result), pets)
+		}
+
+		counts[pets] = c.count
+	}
+
+	if counts[1] != counts[500] {
+		t.Errorf("repositories are called %d times for 1 pet and %d times for 500 pets", counts[1], counts[500])
+	}
+}
+
+func BenchmarkGetByStatus(b *testing.B) {
+	ctx := context.Background()
+	params := &domain.ListParams{Page: 1, Limit: 500}
+
+	for _, pets := range []int{1, 500} {
+		b.Run(fmt.Sprintf("pets=%d", pets), func(b *testing.B) {
+			usecase, c := newCountingUsecase(pets)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := usecase.GetByStatus(ctx, domain.PetStatusAvailable, params); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(c.count)/float64(b.N), "calls/op")
+		})
+	}
+}
+
+// emptyCategoryRepo has no categories.
+type emptyCategoryRepo struct {
+	domain.CategoryRepository
+}
+
+func (emptyCategoryRepo) GetByIds(context.Context, []int) (map[int]*domain.Category, error) {
+	return map[int]*domain.Category{}, nil
+}
+
+func TestMissingCategoryIsInternalError(t *testing.T) {
+	c := &calls{}
+	usecase := NewPetUsecase(&countingPetRepo{calls: c, pets: 1}, emptyCategoryRepo{},
+		&countingTagRepo{calls: c}, &countingPhotoRepo{calls: c}, nil, nil, stubImageProcessor{}, nil)
+
+	_, _, err := usecase.GetByStatus(context.Background(), domain.PetStatusAvailable,
+		&domain.ListParams{Page: 1, Limit: 20})
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+
+	if kind := domain.KindOf(err); kind != domain.KindInternal {
+		t.Errorf("got kind %d, want internal", kind)
+	}
+}
+
+// newMemoryUsecase create usecase over memory repositories, pets have no photos, so blob store is not set.
+func newMemoryUsecase(db *memory.DB) domain.PetUsecase {
+	return NewPetUsecase(repository.NewMemoryPetRepository(db), repository.NewMemoryCategoryRepository(db),
+		repository.NewMemoryTagRepository(db), repository.NewMemoryPhotoRepository(db),
+		_orderRepository.NewMemoryOrderRepository(db), nil, stubImageProcessor{}, memory.NewTxManager(db))
+}
+
+func TestDeletePetWithOrders(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	usecase := newMemoryUsecase(db)
+
+	pet := &domain.Pet{
+		Category: &domain.Category{Name: "dogs"},
+		Name:     "rex",
+		Tags:     []*domain.Tag{{Name: "friendly"}},
+		Status:   domain.PetStatusPending,
+	}
+	if err := usecase.Create(ctx, pet); err != nil {
+		t.Fatalf("create pet: %v", err)
+	}
+
+	err := db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Users.Put(1, domain.User{Id: 1, Username: "alice", Role: domain.RoleCustomer})
+		tables.Orders.Put(1, domain.Order{Id: 1, UserId: 1, PetId: pet.Id, Status: domain.PlacedOrderStatus})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// orders reference pet, as foreign key of postgres
+	if err := usecase.Delete(ctx, pet.Id); !errors.Is(err, domain.ErrPetHasOrders) {
+		t.Fatalf("got %v, want %v", err, domain.ErrPetHasOrders)
+	}
+
+	// tags are removed before pet, so they are restored by rollback
+	got, err := usecase.Get(ctx, pet.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Tags) != 1 || got.Tags[0].Name != "friendly" {
+		t.Errorf("got tags %+v after failed delete, want friendly", got.Tags)
+	}
+
+	err = db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Orders.Delete(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := usecase.Delete(ctx, pet.Id); err != nil {
+		t.Fatalf("delete pet without orders: %v", err)
+	}
+
+	if _, err := usecase.Get(ctx, pet.Id); !errors.Is(err, domain.ErrPetNotFound) {
+		t.Errorf("got %v after delete, want %v", err, domain.ErrPetNotFound)
+	}
+}
+
+func TestUploadImageOfMissingPet(t *testing.T) {
+	usecase := newMemoryUsecase(memory.NewDB())
+
+	err := usecase.UploadImage(context.Background(), &domain.PhotoDTO{PetId: 1}, nil)
+	if !errors.Is(err, domain.ErrPetNotFound) {
+		t.Errorf("got %v, want %v", err, domain.ErrPetNotFound)
+	}
+}
+
+func TestUpdateStatusOfPetWithActiveOrder(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	usecase := newMemoryUsecase(db)
+
+	pet := &domain.Pet{Category: &domain.Category{Name: "dogs"}, Name: "rex", Status: domain.PetStatusPending}
+	if err := usecase.Create(ctx, pet); err != nil {
+		t.Fatalf("create pet: %v", err)
+	}
+
+	err := db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Users.Put(1, domain.User{Id: 1, Username: "alice", Role: domain.RoleCustomer})
+		tables.Orders.Put(1, domain.Order{Id: 1, UserId: 1, PetId: pet.Id, Status: domain.PlacedOrderStatus})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reserved pet can not be made available for second order
+	update := &domain.Pet{Id: pet.Id, Category: pet.Category, Name: "rex", Status: domain.PetStatusAvailable}
+	if err := usecase.Update(ctx, update); !errors.Is(err, domain.ErrPetHasActiveOrder) {
+		t.Fatalf("got %v, want %v", err, domain.ErrPetHasActiveOrder)
+	}
+
+	got, err := usecase.Get(ctx, pet.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Status != domain.PetStatusPending {
+		t.Errorf("got status %s after rejected update, want %s", got.Status, domain.PetStatusPending)
+	}
+
+	// other fields of reserved pet can be changed
+	update = &domain.Pet{Id: pet.Id, Category: pet.Category, Name: "max", Status: domain.PetStatusPending}
+	if err := usecase.Update(ctx, update); err != nil {
+		t.Fatalf("update name: %v", err)
+	}
+
+	err = db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Orders.Put(1, domain.Order{Id: 1, UserId: 1, PetId: pet.Id, Status: domain.CancelledOrderStatus})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update = &domain.Pet{Id: pet.Id, Category: pet.Category, Name: "max", Status: domain.PetStatusAvailable}
+	if err := usecase.Update(ctx, update); err != nil {
+		t.Errorf("update status of pet without active order: %v", err)
+	}
+}
+
+// failingBlobStore fails to delete blobs and records keys of deleted blobs.
+type failingBlobStore struct {
+	domain.BlobStore
+	deleted []string
+}
+
+func (f *failingBlobStore) Delete(_ context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
+	return errors.New("storage is not available")
+}
+
+func TestDeletePetWithFailedPhotoRemoval(t *testing.T) {
+	db := memory.NewDB()
+	blobStore := &failingBlobStore{}
+	usecase := NewPetUsecase(repository.NewMemoryPetRepository(db), repository.NewMemoryCategoryRepository(db),
+		repository.NewMemoryTagRepository(db), repository.NewMemoryPhotoRepository(db),
+		_orderRepository.NewMemoryOrderRepository(db), blobStore, stubImageProcessor{}, memory.NewTxManager(db))
+
+	core, logs := observer.New(zap.ErrorLevel)
+	ctx := logging.WithLogger(context.Background(), zap.New(core))
+
+	pet := &domain.Pet{Category: &domain.Category{Name: "dogs"}, Name: "rex", Status: domain.PetStatusAvailable}
+	if err := usecase.Create(ctx, pet); err != nil {
+		t.Fatalf("create pet: %v", err)
+	}
+
+	err := db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Photos.Put(1, domain.PhotoDTO{Id: 1, PetId: pet.Id})
+		tables.Photos.Put(2, domain.PhotoDTO{Id: 2, PetId: pet.Id})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pet is deleted by commit, so failed removal of files is not error of request
+	if err := usecase.Delete(ctx, pet.Id); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	if _, err := usecase.Get(ctx, pet.Id); !errors.Is(err, domain.ErrPetNotFound) {
+		t.Errorf("got %v after delete, want %v", err, domain.ErrPetNotFound)
+	}
+
+	// photo and small thumbnail of each photo
+	if len(blobStore.deleted) != 4 {
+		t.Errorf("got deleted blobs %v, want files of both photos", blobStore.deleted)
+	}
+
+	if logs.Len() != 2 {
+		t.Errorf("got %d error logs, want one per photo", logs.Len())
+	}
+}
@@ -0,0 +1,116 @@
+package responder
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeXML         = "application/xml"
+	ContentTypeProblemJSON = "application/problem+json"
+	ContentTypeProblemXML  = "application/problem+xml"
+)
+
+// format is format of responses, which client accepts.
+type format struct {
+	xml bool
+	// problem is true if errors are written as problem details
+	problem bool
+}
+
+func (f format) contentType() string {
+	if f.xml {
+		return ContentTypeXML
+	}
+
+	return ContentTypeJSON
+}
+
+func (f format) problemContentType() string {
+	if f.xml {
+		return ContentTypeProblemXML
+	}
+
+	return ContentTypeProblemJSON
+}
+
+func isXML(contentType string) bool {
+	return contentType == ContentTypeXML || contentType == ContentTypeProblemXML
+}
+
+// negotiatedWriter gives format of request to responder, which has only writer.
+type negotiatedWriter struct {
+	http.ResponseWriter
+	format format
+}
+
+func (w *negotiatedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Negotiate choose format of responses by Accept header of request.
+// Xml is used if client prefers application/xml or text/xml, otherwise json.
+// Errors are written as problem details if client accepts application/problem+json
+// or application/problem+xml, otherwise as Response.
+// It must be used on root router after logging.Middleware.
+func Negotiate(next http.Handler) http.Handler {
+	hfn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&negotiatedWriter{ResponseWriter: w, format: negotiate(r.Header.Get("Accept"))}, r)
+	}
+
+	return http.HandlerFunc(hfn)
+}
+
+// negotiate compare quality of json and xml in accept header, wildcards mean json.
+func negotiate(accept string) format {
+	var jsonQ, xmlQ, problemJSONQ, problemXMLQ float64
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case ContentTypeJSON, "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		case ContentTypeXML, "text/xml":
+			xmlQ = max(xmlQ, q)
+		case ContentTypeProblemJSON:
+			jsonQ = max(jsonQ, q)
+			problemJSONQ = q
+		case ContentTypeProblemXML:
+			xmlQ = max(xmlQ, q)
+			problemXMLQ = q
+		}
+	}
+
+	if xmlQ > jsonQ {
+		return format{xml: true, problem: problemXMLQ > 0}
+	}
+
+	return format{problem: problemJSONQ > 0}
+}
+
+// formatOf returns format of writer created by Negotiate, json without problem details by default.
+func formatOf(w http.ResponseWriter) format {
+	for {
+		switch writer := w.(type) {
+		case *negotiatedWriter:
+			return writer.format
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return format{}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package responder
+
+import (
+	"encoding/xml"
+	"net/http"
+	"petstore/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// ProblemTypeBase is prefix of type of problems, type does not depend on address of server.
+const ProblemTypeBase = "urn:petstore:problem:"
+
+// problemTypes are types and default codes of problems by status code.
+var problemTypes = map[int]struct {
+	name string
+	code string
+}{
+	http.StatusBadRequest:          {name: "bad-request", code: "bad_request"},
+	http.StatusUnauthorized:        {name: "unauthorized", code: "unauthorized"},
+	http.StatusForbidden:           {name: "forbidden", code: "forbidden"},
+	http.StatusNotFound:            {name: "not-found", code: "not_found"},
+	http.StatusConflict:            {name: "conflict", code: "conflict"},
+	http.StatusInternalServerError: {name: "internal", code: "internal_error"},
+	http.StatusServiceUnavailable:  {name: "service-unavailable", code: "service_unavailable"},
+}
+
+// Problem is error response in format of RFC 7807.
+type Problem struct {
+	XMLName xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type    string   `json:"type" xml:"type"`
+	Title   string   `json:"title" xml:"title"`
+	Status  int      `json:"status" xml:"status"`
+	Detail  string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	// Code is stable machine readable code of error, e.g. "pet_not_found"
+	Code       string             `json:"code" xml:"code"`
+	Violations []domain.Violation `json:"violations,omitempty" xml:"violations>violation,omitempty"`
+}
+
+// NewProblem create problem of error, code and violations are taken from domain.Error.
+func NewProblem(statusCode int, err error) *Problem {
+	problemType, ok := problemTypes[statusCode]
+	if !ok {
+		problemType.name = strconv.Itoa(statusCode)
+		problemType.code = strings.ToLower(strings.ReplaceAll(http.StatusText(statusCode), " ", "_"))
+	}
+
+	code := domain.CodeOf(err)
+	if code == "" {
+		code = problemType.code
+	}
+
+	return &Problem{
+		Type:       ProblemTypeBase + problemType.name,
+		Title:      http.StatusText(statusCode),
+		Status:     statusCode,
+		Detail:     err.Error(),
+		Code:       code,
+		Violations: domain.ViolationsOf(err),
+	}
+}
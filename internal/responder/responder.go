@@ -5,25 +5,53 @@ import (
 	"errors"
 	"github.com/ptflp/godecoder"
 	"net/http"
+	"petstore/internal/domain"
+	"petstore/internal/logging"
 
 	"go.uber.org/zap"
 )
 
 //go:generate easytags $GOFILE
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page  int `json:"page" xml:"page"`
+	Limit int `json:"limit" xml:"limit"`
+	Total int `json:"total" xml:"total"`
+	Pages int `json:"pages" xml:"pages"`
+}
+
+// NewPagination create pagination metadata of list response, total is number of items on all pages.
+func NewPagination(params *domain.ListParams, total int) *Pagination {
+	return &Pagination{
+		Page:  params.Page,
+		Limit: params.Limit,
+		Total: total,
+		Pages: (total + params.Limit - 1) / params.Limit,
+	}
 }
 
 type Responder interface {
-	OutputJSON(w http.ResponseWriter, responseData interface{})
+	// Output write data in format negotiated by Negotiate, json by default.
+	Output(w http.ResponseWriter, responseData interface{})
+	// OutputWithStatus is like Output, but with status code other than 200.
+	OutputWithStatus(w http.ResponseWriter, statusCode int, responseData interface{})
 
 	ErrorUnauthorized(w http.ResponseWriter, err error)
 	ErrorBadRequest(w http.ResponseWriter, err error)
 	ErrorForbidden(w http.ResponseWriter, err error)
 	ErrorInternal(w http.ResponseWriter, err error)
 	ErrorNotFound(w http.ResponseWriter, err error)
+	ErrorConflict(w http.ResponseWriter, err error)
+	ErrorServiceUnavailable(w http.ResponseWriter, err error)
+	ErrorRequestEntityTooLarge(w http.ResponseWriter, err error)
+	// Error write error with status code by its domain.ErrorKind, errors without kind are internal.
+	Error(w http.ResponseWriter, err error)
 }
 
 type Respond struct {
@@ -32,80 +60,114 @@ type Respond struct {
 }
 
 func (r *Respond) ErrorNotFound(w http.ResponseWriter, err error) {
-	r.log.Info("http response not found status code", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusNotFound)
-	if err := r.Encode(w, Response{
+	r.logger(w).Info("http response not found status code", zap.Error(err))
+	r.outputError(w, http.StatusNotFound, err)
+}
+
+func (r *Respond) ErrorConflict(w http.ResponseWriter, err error) {
+	r.logger(w).Info("http response conflict status code", zap.Error(err))
+	r.outputError(w, http.StatusConflict, err)
+}
+
+func (r *Respond) ErrorServiceUnavailable(w http.ResponseWriter, err error) {
+	r.logger(w).Warn("http response service unavailable", zap.Error(err))
+	r.outputError(w, http.StatusServiceUnavailable, err)
+}
+
+func (r *Respond) ErrorRequestEntityTooLarge(w http.ResponseWriter, err error) {
+	r.logger(w).Info("http response request entity too large", zap.Error(err))
+	r.outputError(w, http.StatusRequestEntityTooLarge, err)
+}
+
+func (r *Respond) Error(w http.ResponseWriter, err error) {
+	switch domain.KindOf(err) {
+	case domain.KindNotFound:
+		r.ErrorNotFound(w, err)
+	case domain.KindConflict:
+		r.ErrorConflict(w, err)
+	case domain.KindValidation:
+		r.ErrorBadRequest(w, err)
+	case domain.KindUnauthorized:
+		r.ErrorUnauthorized(w, err)
+	case domain.KindForbidden:
+		r.ErrorForbidden(w, err)
+	default:
+		r.ErrorInternal(w, err)
+	}
+}
+
+// outputError write error in format negotiated by Negotiate: problem details or Response.
+func (r *Respond) outputError(w http.ResponseWriter, statusCode int, err error) {
+	format := formatOf(w)
+	if format.problem {
+		r.write(w, statusCode, format.problemContentType(), NewProblem(statusCode, err))
+		return
+	}
+
+	r.write(w, statusCode, format.contentType(), Response{
 		Success: false,
 		Message: err.Error(),
 		Data:    nil,
-	}); err != nil {
-		r.log.Info("response writer error on write", zap.Error(err))
+	})
+}
+
+// write encode data as xml or json by content type.
+func (r *Respond) write(w http.ResponseWriter, statusCode int, contentType string, data interface{}) {
+	w.Header().Set("Content-Type", contentType+";charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	var err error
+	if isXML(contentType) {
+		err = encodeXML(w, data)
+	} else {
+		err = r.Encode(w, data)
+	}
+
+	if err != nil {
+		r.logger(w).Error("responder encode error", zap.String("contentType", contentType), zap.Error(err))
 	}
 }
 
+// logger returns logger of request, so logs have request id, route and user.
+func (r *Respond) logger(w http.ResponseWriter) *zap.Logger {
+	if logger := logging.FromWriter(w); logger != nil {
+		return logger
+	}
+
+	return r.log
+}
+
 func NewResponder(decoder godecoder.Decoder, logger *zap.Logger) Responder {
 	return &Respond{log: logger, Decoder: decoder}
 }
 
-func (r *Respond) OutputJSON(w http.ResponseWriter, responseData interface{}) {
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	if err := r.Encode(w, responseData); err != nil {
-		r.log.Error("responder json encode error", zap.Error(err))
-	}
+func (r *Respond) Output(w http.ResponseWriter, responseData interface{}) {
+	r.OutputWithStatus(w, http.StatusOK, responseData)
+}
+
+func (r *Respond) OutputWithStatus(w http.ResponseWriter, statusCode int, responseData interface{}) {
+	r.write(w, statusCode, formatOf(w).contentType(), responseData)
 }
 
 func (r *Respond) ErrorBadRequest(w http.ResponseWriter, err error) {
-	r.log.Info("http response bad request status code", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusBadRequest)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Info("response writer error on write", zap.Error(err))
-	}
+	r.logger(w).Info("http response bad request status code", zap.Error(err))
+	r.outputError(w, http.StatusBadRequest, err)
 }
 
 func (r *Respond) ErrorForbidden(w http.ResponseWriter, err error) {
-	r.log.Warn("http response forbidden", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusForbidden)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Error("response writer error on write", zap.Error(err))
-	}
+	r.logger(w).Warn("http response forbidden", zap.Error(err))
+	r.outputError(w, http.StatusForbidden, err)
 }
 
 func (r *Respond) ErrorUnauthorized(w http.ResponseWriter, err error) {
-	r.log.Warn("http response Unauthorized", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusUnauthorized)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Error("response writer error on write", zap.Error(err))
-	}
+	r.logger(w).Warn("http response Unauthorized", zap.Error(err))
+	r.outputError(w, http.StatusUnauthorized, err)
 }
 
 func (r *Respond) ErrorInternal(w http.ResponseWriter, err error) {
 	if errors.Is(err, context.Canceled) {
 		return
 	}
-	r.log.Error("http response internal error", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Error("response writer error on write", zap.Error(err))
-	}
+	r.logger(w).Error("http response internal error", zap.Error(err))
+	r.outputError(w, http.StatusInternalServerError, err)
 }
@@ -0,0 +1,75 @@
+package responder
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+)
+
+func encodeXML(w io.Writer, data interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	return xml.NewEncoder(w).Encode(data)
+}
+
+// MarshalXML encode response as <response> element. Structs and lists in data keep
+// their element names, e.g. <data><Pet>...</Pet><Pet>...</Pet></data>, other values are text of <data>.
+func (r Response) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "response"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := e.EncodeElement(r.Success, element("success")); err != nil {
+		return err
+	}
+
+	if r.Message != "" {
+		if err := e.EncodeElement(r.Message, element("message")); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeData(e, r.Data); err != nil {
+		return err
+	}
+
+	if r.Pagination != nil {
+		if err := e.EncodeElement(r.Pagination, element("pagination")); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func encodeData(e *xml.Encoder, data interface{}) error {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid, reflect.Ptr:
+		return nil
+	case reflect.Struct, reflect.Slice, reflect.Array:
+		dataStart := element("data")
+		if err := e.EncodeToken(dataStart); err != nil {
+			return err
+		}
+
+		if err := e.Encode(data); err != nil {
+			return err
+		}
+
+		return e.EncodeToken(dataStart.End())
+	default:
+		return e.EncodeElement(data, element("data"))
+	}
+}
+
+func element(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
@@ -0,0 +1,212 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"petstore/internal/logging"
+	"strings"
+	"time"
+)
+
+// Runner runs queries built by squirrel with spans.
+// Rows of query are read by QueryRows, squirrel QueryContext is not supported,
+// because span of query must end only after rows are read.
+type Runner interface {
+	sq.BaseRunner
+	sq.ExecerContext
+	sq.QueryRowerContext
+
+	// QueryRows run query, its span ends when rows are closed, read to the end or Err is called.
+	QueryRows(ctx context.Context, query sq.Sqlizer) (*Rows, error)
+}
+
+// tracedConn start span of each query with context, queries without context are not traced.
+// It does not implement sq.StdSqlCtx, so squirrel does not replace its QueryRowContext.
+type tracedConn struct {
+	conn sq.StdSqlCtx
+}
+
+// Conn wrap connection or transaction, so queries run by squirrel have spans with sql statement.
+func Conn(conn sq.StdSqlCtx) Runner {
+	return &tracedConn{conn: conn}
+}
+
+func (c *tracedConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.Exec(query, args...)
+}
+
+func (c *tracedConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.Query(query, args...)
+}
+
+func (c *tracedConn) QueryRow(query string, args ...interface{}) sq.RowScanner {
+	return c.conn.QueryRow(query, args...)
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+	defer logQuery(ctx, query, time.Now())
+
+	res, err := c.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		RecordError(span, err)
+		logQueryError(ctx, query, err)
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", n))
+	}
+
+	return res, nil
+}
+
+func (c *tracedConn) QueryRows(ctx context.Context, query sq.Sqlizer) (*Rows, error) {
+	statement, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, statement)
+	start := time.Now()
+
+	rows, err := c.conn.QueryContext(ctx, statement, args...)
+	if err != nil {
+		RecordError(span, err)
+		logQueryError(ctx, statement, err)
+		span.End()
+		logQuery(ctx, statement, start)
+
+		return nil, err
+	}
+
+	return &Rows{Rows: rows, ctx: ctx, span: span, statement: statement, start: start}, nil
+}
+
+func (c *tracedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	ctx, span := startQuery(ctx, query)
+
+	return &Row{row: c.conn.QueryRowContext(ctx, query, args...), ctx: ctx, span: span, statement: query,
+		start: time.Now()}
+}
+
+// Rows count read rows and end span of query, when rows are closed, read to the end or Err is called.
+type Rows struct {
+	*sql.Rows
+
+	ctx       context.Context
+	span      trace.Span
+	statement string
+	start     time.Time
+	read      int
+	ended     bool
+}
+
+func (r *Rows) Next() bool {
+	if r.Rows.Next() {
+		r.read++
+		return true
+	}
+
+	// rows are closed by sql after last row
+	r.end(r.Rows.Err())
+	return false
+}
+
+func (r *Rows) Err() error {
+	err := r.Rows.Err()
+	r.end(err)
+
+	return err
+}
+
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.end(errors.Join(r.Rows.Err(), err))
+
+	return err
+}
+
+// end - record number of read rows and end span, only first call has effect.
+func (r *Rows) end(err error) {
+	if r.ended {
+		return
+	}
+	r.ended = true
+
+	if err != nil {
+		RecordError(r.span, err)
+		logQueryError(r.ctx, r.statement, err)
+	}
+
+	r.span.SetAttributes(attribute.Int("db.rows_read", r.read))
+	r.span.End()
+	logQuery(r.ctx, r.statement, r.start)
+}
+
+// Row ends span of query on Scan.
+type Row struct {
+	row *sql.Row
+
+	ctx       context.Context
+	span      trace.Span
+	statement string
+	start     time.Time
+}
+
+// Scan row, sql.ErrNoRows is returned, but it is not recorded as error of span.
+func (r *Row) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+
+	read := 1
+	if err != nil {
+		read = 0
+		if !errors.Is(err, sql.ErrNoRows) {
+			RecordError(r.span, err)
+			logQueryError(r.ctx, r.statement, err)
+		}
+	}
+
+	r.span.SetAttributes(attribute.Int("db.rows_read", read))
+	r.span.End()
+	logQuery(r.ctx, r.statement, r.start)
+
+	return err
+}
+
+// logQuery - log query with logger of request on debug level.
+func logQuery(ctx context.Context, query string, start time.Time) {
+	logging.FromContext(ctx).Debug("sql query",
+		zap.String("statement", query), zap.Duration("duration", time.Since(start)))
+}
+
+// logQueryError - log failed query with logger of request.
+// Errors are also returned to usecases, but only statement shows which query of repository failed.
+func logQueryError(ctx context.Context, query string, err error) {
+	logging.FromContext(ctx).Warn("sql query failed", zap.String("statement", query), zap.Error(err))
+}
+
+// startQuery - start client span named by sql operation, e.g. "SELECT".
+// Arguments are not recorded, because they can contain personal data.
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := "SQL"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return Tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBStatementKey.String(query),
+		),
+	)
+}
@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"io"
+	"testing"
+)
+
+// countDriver returns as many rows as first argument of query, its queries can not be prepared.
+type countDriver struct{}
+
+func (countDriver) Open(string) (driver.Conn, error) {
+	return countConn{}, nil
+}
+
+type countConn struct{}
+
+func (countConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (countConn) Close() error {
+	return nil
+}
+
+func (countConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (countConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	return &countRows{total: args[0].Value.(int64)}, nil
+}
+
+type countRows struct {
+	total, read int64
+}
+
+func (r *countRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *countRows) Close() error {
+	return nil
+}
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.read == r.total {
+		return io.EOF
+	}
+
+	r.read++
+	dest[0] = r.read
+	return nil
+}
+
+func init() {
+	sql.Register("tracing-count", countDriver{})
+}
+
+// setup set recorder of spans as global provider and open db of countDriver.
+func setup(t *testing.T) (Runner, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := sql.Open("tracing-count", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return Conn(db), recorder
+}
+
+// rowsRead returns db.rows_read attribute of the only ended span.
+func rowsRead(t *testing.T, recorder *tracetest.SpanRecorder) int64 {
+	t.Helper()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d ended spans, want 1", len(spans))
+	}
+
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == attribute.Key("db.rows_read") {
+			return attr.Value.AsInt64()
+		}
+	}
+
+	t.Fatal("span has no db.rows_read attribute")
+	return 0
+}
+
+func TestRowsSpanEndsAfterRead(t *testing.T) {
+	ctx := context.Background()
+	runner, recorder := setup(t)
+
+	rows, err := runner.QueryRows(ctx, sq.Select("id").From("pets").Where("n = ?", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("span is ended before rows are read")
+	}
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := rowsRead(t, recorder); n != 3 {
+		t.Errorf("got %d rows read, want 3", n)
+	}
+
+	if name := recorder.Ended()[0].Name(); name != "SELECT" {
+		t.Errorf("got span %q, want SELECT", name)
+	}
+}
+
+func TestRowsSpanEndsOnClose(t *testing.T) {
+	ctx := context.Background()
+	runner, recorder := setup(t)
+
+	rows, err := runner.QueryRows(ctx, sq.Select("id").From("pets").Where("n = ?", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rows.Next() {
+		t.Fatal("no rows")
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	if n := rowsRead(t, recorder); n != 1 {
+		t.Errorf("got %d rows read, want 1", n)
+	}
+}
+
+func TestRowSpanEndsOnScan(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		total int
+		read  int64
+		err   error
+	}{
+		{name: "found", total: 1, read: 1},
+		{name: "not found", total: 0, read: 0, err: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, recorder := setup(t)
+
+			row := sq.Select("id").From("pets").Where("n = ?", tt.total).RunWith(runner).QueryRowContext(ctx)
+			if len(recorder.Ended()) != 0 {
+				t.Fatal("span is ended before scan")
+			}
+
+			var id int
+			if err := row.Scan(&id); !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+
+			if n := rowsRead(t, recorder); n != tt.read {
+				t.Errorf("got %d rows read, want %d", n, tt.read)
+			}
+
+			// missing row is not error of query
+			if status := recorder.Ended()[0].Status().Code; status == codes.Error {
+				t.Errorf("span has error status")
+			}
+		})
+	}
+}
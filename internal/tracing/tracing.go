@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "petstore"
+
+// Tracer returns tracer of application.
+// It uses global provider, so spans are not recorded until provider is set.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start start span of internal operation, e.g. method of usecase.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End record error of operation and end span.
+// It is used with named error result:
+//
+//	ctx, span := tracing.Start(ctx, "petUsecase.Get")
+//	defer tracing.End(span, &err)
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		RecordError(span, *err)
+	}
+
+	span.End()
+}
+
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
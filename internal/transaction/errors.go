@@ -0,0 +1,24 @@
+package transaction
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// IsUniqueViolation - change of row violates unique constraint, e.g. username is taken.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// IsForeignKeyViolation - change of row violates foreign key constraint with name, e.g. "orders_pet_id_fkey".
+// Constraint is violated by row, which references missing row, and by delete of referenced row.
+func IsForeignKeyViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation && pqErr.Constraint == constraint
+}
@@ -0,0 +1,41 @@
+package transaction
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"testing"
+)
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	violation := &pq.Error{Code: "23503", Constraint: "orders_pet_id_fkey"}
+
+	tests := []struct {
+		name       string
+		err        error
+		constraint string
+		want       bool
+	}{
+		{name: "same constraint", err: violation, constraint: "orders_pet_id_fkey", want: true},
+		{name: "wrapped", err: fmt.Errorf("insert order: %w", violation), constraint: "orders_pet_id_fkey", want: true},
+		{name: "other constraint", err: violation, constraint: "orders_user_id_fkey"},
+		{name: "unique violation", err: &pq.Error{Code: "23505", Constraint: "orders_pet_id_fkey"},
+			constraint: "orders_pet_id_fkey"},
+		{name: "nil", constraint: "orders_pet_id_fkey"},
+	}
+
+	for _, tt := range tests {
+		if got := IsForeignKeyViolation(tt.err, tt.constraint); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(&pq.Error{Code: "23505"}) {
+		t.Error("unique violation is not detected")
+	}
+
+	if IsUniqueViolation(&pq.Error{Code: "23503"}) {
+		t.Error("foreign key violation is detected as unique violation")
+	}
+}
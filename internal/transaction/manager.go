@@ -0,0 +1,57 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"petstore/internal/tracing"
+)
+
+type txKey struct{}
+
+type txManager struct {
+	Conn *sql.DB
+}
+
+func NewTxManager(conn *sql.DB) domain.TxManager {
+	return &txManager{Conn: conn}
+}
+
+func (t *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer tracing.End(span, &err)
+
+	tx, err := t.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logging.FromContext(ctx).Error("transaction rollback failed", zap.NamedError("cause", err),
+				zap.Error(rollbackErr))
+			return fmt.Errorf("%w, rollback failed: %v", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Conn returns transaction from context if it exists, else conn.
+// Repositories must run queries on it to take part in transaction, queries are traced.
+func Conn(ctx context.Context, conn *sql.DB) tracing.Runner {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tracing.Conn(tx)
+	}
+
+	return tracing.Conn(conn)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 	"net/http"
 	"petstore/internal/domain"
+	"petstore/internal/logging"
 	"petstore/internal/responder"
 )
 
@@ -23,17 +24,48 @@ func Authenticator(resp responder.Responder, userUsecase domain.UserUsecase) fun
 				return
 			}
 
-			if isAuth, err := userUsecase.IsAuthenticated(r.Context(), token); err != nil || !isAuth {
-				if err != nil {
-					resp.ErrorInternal(w, err)
-					return
-				} else {
+			principal, err := userUsecase.Authenticate(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, domain.ErrSessionNotFound) {
 					resp.ErrorUnauthorized(w, errors.New("session was logout"))
-					return
+				} else {
+					resp.Error(w, err)
 				}
+
+				return
+			}
+
+			ctx := logging.WithUser(r.Context(), principal.UserId, principal.Username)
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(ctx, principal)))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// OptionalAuthenticator is like Authenticator, but it does not reject anonymous clients.
+// Principal is set to context only if token is valid and session exists.
+func OptionalAuthenticator(resp responder.Responder, userUsecase domain.UserUsecase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			token, _, err := jwtauth.FromContext(r.Context())
+			if err != nil || token == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := userUsecase.Authenticate(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, domain.ErrSessionNotFound) {
+					next.ServeHTTP(w, r)
+				} else {
+					resp.Error(w, err)
+				}
+
+				return
 			}
 
-			next.ServeHTTP(w, r)
+			ctx := logging.WithUser(r.Context(), principal.UserId, principal.Username)
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(ctx, principal)))
 		}
 		return http.HandlerFunc(hfn)
 	}
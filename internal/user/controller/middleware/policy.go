@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"petstore/internal/domain"
+	"petstore/internal/responder"
+)
+
+// RequirePermission allows request only if authenticated user has permission.
+// Must be used after Authenticator.
+func RequirePermission(resp responder.Responder, permission domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := domain.PrincipalFromContext(r.Context())
+			if !ok {
+				resp.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+				return
+			}
+
+			if !principal.Can(permission) {
+				resp.ErrorForbidden(w, fmt.Errorf("%w: permission %s is required", domain.ErrForbidden, permission))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
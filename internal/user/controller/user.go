@@ -1,14 +1,16 @@
 package controller
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/jwtauth/v5"
 	"net/http"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
 	"petstore/internal/responder"
+	"petstore/internal/user/controller/middleware"
+	"petstore/internal/validation"
 )
 
 type UserController struct {
@@ -16,43 +18,58 @@ type UserController struct {
 	responder   responder.Responder
 }
 
-func NewUserController(r chi.Router, resp responder.Responder, us domain.UserUsecase) {
+// NewUserController register routes of users.
+// authenticator is middleware which authenticate user, it is used for routes which require authentication.
+// optionalAuthenticator is used for public routes, which show more data to authenticated users.
+func NewUserController(r chi.Router, resp responder.Responder, us domain.UserUsecase,
+	authenticator func(http.Handler) http.Handler, optionalAuthenticator func(http.Handler) http.Handler) {
 	u := UserController{userUsecase: us, responder: resp}
 	r.Route("/user", func(r chi.Router) {
 		r.Post("/login", u.Login)
 		r.Get("/logout", u.Logout)
-		r.Post("/createWithList", u.CreateWithList)
 
 		r.Post("/", u.Create)
-		r.Get("/{username}", u.Get)
-		r.Put("/{username}", u.Update)
-		r.Delete("/{username}", u.Delete)
+		r.With(optionalAuthenticator).Get("/{username}", u.Get)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authenticator)
+
+			r.Put("/{username}", u.Update)
+			r.Delete("/{username}", u.Delete)
+
+			r.With(middleware.RequirePermission(resp, domain.PermissionUserManage)).
+				Post("/createWithList", u.CreateWithList)
+			r.With(middleware.RequirePermission(resp, domain.PermissionUserManage)).
+				Get("/", u.List)
+		})
 	})
 }
 
 // Create this function creates a new user
 //
 // @Summary		Create a new user
+// @Description	New user is always customer.
 // @Tags		user
-// @Accept		json
-// @Produce		json
-// @Param		pet		body		domain.User			true	"User to add to the store"
+// @Accept		json,application/xml
+// @Produce		json,application/xml
+// @Param		user	body		domain.UserInput	true	"User to add to the store"
 // @Success		200		{string}	string				"User created"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		409		{string}	string				"Username is taken"
 // @Router		/user 	[post]
 func (u *UserController) Create(w http.ResponseWriter, r *http.Request) {
-	var userInput domain.User
-	if err := json.NewDecoder(r.Body).Decode(&userInput); err != nil {
+	var userInput domain.UserInput
+	if err := validation.Decode(w, r, &userInput); err != nil {
 		u.responder.ErrorBadRequest(w, err)
 		return
 	}
 
 	if err := u.userUsecase.Create(r.Context(), &userInput); err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "user created",
 		Data:    nil,
@@ -62,35 +79,34 @@ func (u *UserController) Create(w http.ResponseWriter, r *http.Request) {
 // Get this function is used to get a user
 //
 // @Summary		Get user by username
+// @Description	Email, phone, status and role are visible only to user itself and admin.
 // @Tags		user
-// @Produce		json
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
 //
 // @Param		username path		string				true	"Username of user to return"
 //
-// @Success		200		{object}	domain.User			"Find user by Username"
+// @Success		200		{object}	domain.UserOutput	"Find user by Username"
 // @Failure		400		{string}	string				"Invalid input"
 // @Failure		404		{string}	string				"User not found"
 // @Router		/user/{username} 		[get]
 func (u *UserController) Get(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		u.responder.ErrorBadRequest(w, fmt.Errorf("param username is not set"))
+		u.responder.ErrorBadRequest(w, domain.FieldError("username", fmt.Errorf("param username is not set")))
 		return
 	}
 
-	user, err := u.userUsecase.Get(r.Context(), username)
-	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		u.responder.OutputJSON(w, responder.Response{
-			Success: false,
-			Message: "user not found",
-			Data:    nil,
-		})
+	// principal is not set for anonymous clients
+	principal, _ := domain.PrincipalFromContext(r.Context())
 
+	user, err := u.userUsecase.Get(r.Context(), principal, username)
+	if err != nil {
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "get user",
 		Data:    user,
@@ -100,39 +116,46 @@ func (u *UserController) Get(w http.ResponseWriter, r *http.Request) {
 // Update this function is used to update a user
 //
 // @Summary		Update a user with form data
+// @Description	Users can update only themselves, role can be changed only by admin.
 // @Tags		user
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
 //
-// @Param		user	body		domain.User			true	"User object that needs to update"
+// @Param		username path		string				true	"Username of user to update"
+// @Param		user	body		domain.UserInput	true	"User object that needs to update"
 //
 // @Success		200		{string}	string				"User updated"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"User not found"
-// @Router		/user 	[put]
+// @Failure		409		{string}	string				"Username is taken"
+// @Router		/user/{username} 	[put]
 func (u *UserController) Update(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		u.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		u.responder.ErrorBadRequest(w, fmt.Errorf("param username is not set"))
+		u.responder.ErrorBadRequest(w, domain.FieldError("username", fmt.Errorf("param username is not set")))
 		return
 	}
 
-	var userInput domain.User
-	if err := json.NewDecoder(r.Body).Decode(&userInput); err != nil {
+	var userInput domain.UserInput
+	if err := validation.Decode(w, r, &userInput); err != nil {
 		u.responder.ErrorBadRequest(w, err)
 		return
 	}
 
-	if err := u.userUsecase.Update(r.Context(), username, &userInput); err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			u.responder.ErrorNotFound(w, err)
-		} else {
-			u.responder.ErrorInternal(w, err)
-		}
+	if err := u.userUsecase.Update(r.Context(), principal, username, &userInput); err != nil {
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "user updated",
 		Data:    nil,
@@ -142,33 +165,39 @@ func (u *UserController) Update(w http.ResponseWriter, r *http.Request) {
 // Delete this function is used to delete a user.
 //
 // @Summary		Delete a user by username
+// @Description	Users can delete only themselves, except admin.
 // @Tags		user
-// @Produce		json
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
 //
 // @Param		username path		string				true	"Username of user to delete"
 //
 // @Success		200		{string}	string				"User deleted"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"User not found"
+// @Failure		409		{string}	string				"User has orders"
 // @Router		/user/{username} 	[delete]
 func (u *UserController) Delete(w http.ResponseWriter, r *http.Request) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		u.responder.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+		return
+	}
+
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		u.responder.ErrorBadRequest(w, fmt.Errorf("param username is not set"))
+		u.responder.ErrorBadRequest(w, domain.FieldError("username", fmt.Errorf("param username is not set")))
 		return
 	}
 
-	err := u.userUsecase.Delete(r.Context(), username)
+	err := u.userUsecase.Delete(r.Context(), principal, username)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			u.responder.ErrorNotFound(w, err)
-		} else {
-			u.responder.ErrorInternal(w, err)
-		}
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "user deleted",
 		Data:    nil,
@@ -178,63 +207,105 @@ func (u *UserController) Delete(w http.ResponseWriter, r *http.Request) {
 // CreateWithList this function creates a new users
 //
 // @Summary		Create a list of new users
+// @Description	Only for admin, users can have any role.
 // @Tags		user
-// @Accept		json
-// @Produce		json
-// @Param		users		body		[]domain.User		true	"Users to add to the store"
+// @Accept		json,application/xml
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+// @Param		users		body		[]domain.UserInput	true	"Users to add to the store"
 // @Success		200		{string}	string				"Users created"
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
 // @Failure		404		{string}	string				"User not found"
+// @Failure		409		{string}	string				"Username is taken"
 // @Router		/user/createWithList	[post]
 func (u *UserController) CreateWithList(w http.ResponseWriter, r *http.Request) {
-	var userInput []*domain.User
-	if err := json.NewDecoder(r.Body).Decode(&userInput); err != nil {
+	var userInput []*domain.UserInput
+	if err := validation.Decode(w, r, &userInput); err != nil {
 		u.responder.ErrorBadRequest(w, err)
 		return
 	}
 
 	if err := u.userUsecase.CreateList(r.Context(), userInput); err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "users created",
 		Data:    nil,
 	})
 }
 
+// List this function is used to get users
+//
+// @Summary		List users
+// @Description	Only for admin.
+// @Tags		user
+// @Produce		json,application/xml
+// @Security 	ApiKeyAuth
+//
+// @Param		page	query		int					false	"Page number, starts from 1"	default(1)	maximum(1000000)
+// @Param		limit	query		int					false	"Page size"	default(20)	maximum(100)
+// @Param		sort	query		string				false	"Comma separated fields, prefix - for descending order, e.g. -name,id"
+// @Param		filter	query		[]string			false	"Filters in format field:operator:value, operators: eq, ne, lt, lte, gt, gte, like, in (values separated by |)"	collectionFormat(multi)
+//
+// @Success		200		{object}	[]domain.UserOutput	"Page of users"
+// @Failure		400		{string}	string				"Invalid input"
+// @Failure		403		{string}	string				"Forbidden"
+// @Router		/user 	[get]
+func (u *UserController) List(w http.ResponseWriter, r *http.Request) {
+	params, err := listing.ParseParams(r)
+	if err != nil {
+		u.responder.ErrorBadRequest(w, err)
+		return
+	}
+
+	users, total, err := u.userUsecase.List(r.Context(), params)
+	if err != nil {
+		u.responder.Error(w, err)
+		return
+	}
+
+	u.responder.Output(w, responder.Response{
+		Success:    true,
+		Message:    "list users",
+		Data:       users,
+		Pagination: responder.NewPagination(params, total),
+	})
+}
+
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" xml:"username" validate:"required"`
+	Password string `json:"password" xml:"password" validate:"required"`
 }
 
 // Login this function login a user
 //
 // @Summary		Login a user
 // @Tags		user
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
 // @Param		credentials			body				LoginRequest		true	"User credentials"
 // @Success		200		{string}	string				"User login"
 // @Failure		400		{string}	string				"Invalid input"
-// @Failure		404		{string}	string				"User not found"
+// @Failure		401		{string}	string				"Invalid credentials"
 // @Router		/user/login			[post]
 func (u *UserController) Login(w http.ResponseWriter, r *http.Request) {
 	var loginInput LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&loginInput); err != nil {
+	if err := validation.Decode(w, r, &loginInput); err != nil {
 		u.responder.ErrorBadRequest(w, err)
 		return
 	}
 
 	token, err := u.userUsecase.Login(r.Context(), loginInput.Username, loginInput.Password)
 	if err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "user login",
 		Data:    token,
@@ -246,12 +317,13 @@ func (u *UserController) Login(w http.ResponseWriter, r *http.Request) {
 // @Summary		Logout a user
 // @Tags		user
 // @Security 	ApiKeyAuth
-// @Accept		json
-// @Produce		json
+// @Accept		json,application/xml
+// @Produce		json,application/xml
 //
 // @Success		200		{string}	string				"User logout"
 //
 // @Failure		400		{string}	string				"Invalid input"
+// @Failure		401		{string}	string				"Invalid token"
 // @Router		/user/logout		[get]
 func (u *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	token := jwtauth.TokenFromHeader(r)
@@ -262,11 +334,11 @@ func (u *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 
 	err := u.userUsecase.Logout(r.Context(), token)
 	if err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, err)
 		return
 	}
 
-	u.responder.OutputJSON(w, responder.Response{
+	u.responder.Output(w, responder.Response{
 		Success: true,
 		Message: "user logout",
 		Data:    nil,
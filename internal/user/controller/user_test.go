@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/ptflp/godecoder"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"petstore/internal/domain"
+	"petstore/internal/responder"
+	"testing"
+)
+
+type listUserUsecase struct {
+	domain.UserUsecase
+}
+
+func (listUserUsecase) List(context.Context, *domain.ListParams) ([]*domain.UserOutput, int, error) {
+	return []*domain.UserOutput{}, 0, nil
+}
+
+// testAuthenticator authenticate user with role from header, request without header is rejected.
+func testAuthenticator(resp responder.Responder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := r.Header.Get("X-Role")
+			if role == "" {
+				resp.ErrorUnauthorized(w, errors.New("user is not authenticated"))
+				return
+			}
+
+			principal := &domain.Principal{UserId: 1, Username: "user", Role: domain.Role(role)}
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func TestListRequiresUserManage(t *testing.T) {
+	resp := responder.NewResponder(godecoder.NewDecoder(), zap.NewNop())
+	r := chi.NewRouter()
+	NewUserController(r, resp, listUserUsecase{}, testAuthenticator(resp), testAuthenticator(resp))
+
+	tests := []struct {
+		role   domain.Role
+		status int
+	}{
+		{role: "", status: http.StatusUnauthorized},
+		{role: domain.RoleCustomer, status: http.StatusForbidden},
+		{role: domain.RoleStaff, status: http.StatusForbidden},
+		{role: domain.RoleAdmin, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/user/?filter=email:like:example", nil)
+		req.Header.Set("X-Role", string(tt.role))
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("role %q: got status %d, want %d", tt.role, rec.Code, tt.status)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/transaction"
 )
 
 type authRepository struct {
@@ -21,46 +22,41 @@ func (a *authRepository) RegisterSession(ctx context.Context, userId int) (int,
 	query := a.SqlBuilder.Insert("auth")
 	query = query.Columns("user_id").Values(userId).Suffix("RETURNING id")
 
-	raw := query.RunWith(a.Conn).QueryRowContext(ctx)
+	raw := query.RunWith(transaction.Conn(ctx, a.Conn)).QueryRowContext(ctx)
 	var sessionId int
 	err := raw.Scan(&sessionId)
+	if transaction.IsForeignKeyViolation(err, "auth_user_id_fkey") {
+		return 0, domain.ErrUserNotFound
+	}
 
 	return sessionId, err
 }
 
 func (a *authRepository) UnregisterSession(ctx context.Context, sessionId int) error {
 	query := a.SqlBuilder.Delete("auth").Where(sq.Eq{"id": sessionId})
-	_, err := query.RunWith(a.Conn).ExecContext(ctx)
+	_, err := query.RunWith(transaction.Conn(ctx, a.Conn)).ExecContext(ctx)
 
 	return err
 }
 
 func (a *authRepository) UnregisterAllSession(ctx context.Context, userId int) error {
 	query := a.SqlBuilder.Delete("auth").Where(sq.Eq{"user_id": userId})
-	res, err := query.RunWith(a.Conn).ExecContext(ctx)
-
-	if n, err := res.RowsAffected(); err != nil || n == 0 {
-		if err != nil {
-			return err
-		} else {
-			return domain.ErrSessionNotFound
-		}
-	}
+	_, err := query.RunWith(transaction.Conn(ctx, a.Conn)).ExecContext(ctx)
 
 	return err
 }
 
-// ExistsSession checks if a session exists.
-// Returns (false, nil) if no session is found.
-func (a *authRepository) ExistsSession(ctx context.Context, sessionId int) (bool, error) {
-	query := a.SqlBuilder.Select("id").From("auth").Where(sq.Eq{"id": sessionId})
-	row := query.RunWith(a.Conn).QueryRowContext(ctx)
-	var id int
-	err := row.Scan(&id)
+func (a *authRepository) GetPrincipal(ctx context.Context, sessionId int) (*domain.Principal, error) {
+	query := a.SqlBuilder.Select("users.id", "users.username", "users.role").From("auth")
+	query = query.Join("users ON users.id = auth.user_id").Where(sq.Eq{"auth.id": sessionId})
+
+	row := query.RunWith(transaction.Conn(ctx, a.Conn)).QueryRowContext(ctx)
+	var principal domain.Principal
+	err := row.Scan(&principal.UserId, &principal.Username, &principal.Role)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return false, nil
+		return nil, domain.ErrSessionNotFound
 	}
 
-	return err == nil, err
+	return &principal, err
 }
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/memory"
+)
+
+// userFields are fields of user available for sorting and filtering in memory, like userColumns
+var userFields = listing.Fields[domain.User]{
+	"id":         {Kind: listing.KindInt, Value: func(u domain.User) interface{} { return u.Id }},
+	"username":   {Kind: listing.KindString, Value: func(u domain.User) interface{} { return u.Username }},
+	"firstName":  {Kind: listing.KindString, Value: func(u domain.User) interface{} { return u.FirstName }},
+	"lastName":   {Kind: listing.KindString, Value: func(u domain.User) interface{} { return u.LastName }},
+	"email":      {Kind: listing.KindString, Value: func(u domain.User) interface{} { return u.Email }},
+	"phone":      {Kind: listing.KindString, Value: func(u domain.User) interface{} { return u.Phone }},
+	"userStatus": {Kind: listing.KindInt, Value: func(u domain.User) interface{} { return u.UserStatus }},
+	"role":       {Kind: listing.KindEnum, Value: func(u domain.User) interface{} { return string(u.Role) }, Values: roles},
+}
+
+type memoryUserRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryUserRepository(db *memory.DB) domain.UserRepository {
+	return &memoryUserRepository{DB: db}
+}
+
+func (u *memoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	return u.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := findByUsername(tables, user.Username); ok {
+			return domain.ErrUserAlreadyExists
+		}
+
+		user.Id = tables.Users.NextId()
+		tables.Users.Put(user.Id, *user)
+
+		return nil
+	})
+}
+
+func (u *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var user domain.User
+	err := u.DB.Read(ctx, func(tables *memory.Tables) error {
+		var ok bool
+		if user, ok = findByUsername(tables, username); !ok {
+			return domain.ErrUserNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (u *memoryUserRepository) GetIdByUsername(ctx context.Context, username string) (int, error) {
+	user, err := u.GetByUsername(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.Id, nil
+}
+
+func (u *memoryUserRepository) Update(ctx context.Context, username string, user *domain.User) error {
+	return u.DB.Write(ctx, func(tables *memory.Tables) error {
+		current, ok := findByUsername(tables, username)
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+
+		if other, ok := findByUsername(tables, user.Username); ok && other.Id != current.Id {
+			return domain.ErrUserAlreadyExists
+		}
+
+		updated := *user
+		updated.Id = current.Id
+		tables.Users.Put(current.Id, updated)
+
+		return nil
+	})
+}
+
+func (u *memoryUserRepository) Delete(ctx context.Context, username string) error {
+	return u.DB.Write(ctx, func(tables *memory.Tables) error {
+		user, ok := findByUsername(tables, username)
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+
+		// foreign keys of postgres
+		if _, ok := tables.Orders.Find(func(order domain.Order) bool { return order.UserId == user.Id }); ok {
+			return domain.ErrUserHasOrders
+		}
+
+		if _, ok := tables.Sessions.Find(func(session memory.Session) bool { return session.UserId == user.Id }); ok {
+			return fmt.Errorf("user %d is referenced by sessions", user.Id)
+		}
+
+		tables.Users.Delete(user.Id)
+		return nil
+	})
+}
+
+func (u *memoryUserRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.User, int, error) {
+	var page []domain.User
+	var total int
+	err := u.DB.Read(ctx, func(tables *memory.Tables) error {
+		var err error
+		page, total, err = listing.List(tables.Users.Rows(), params, userFields)
+
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*domain.User, 0, len(page))
+	for i := range page {
+		items = append(items, &page[i])
+	}
+
+	return items, total, nil
+}
+
+// findByUsername - find user by unique username.
+func findByUsername(tables *memory.Tables, username string) (domain.User, bool) {
+	return tables.Users.Find(func(user domain.User) bool {
+		return user.Username == username
+	})
+}
+
+type memoryAuthRepository struct {
+	DB *memory.DB
+}
+
+func NewMemoryAuthRepository(db *memory.DB) domain.AuthRepository {
+	return &memoryAuthRepository{DB: db}
+}
+
+func (a *memoryAuthRepository) RegisterSession(ctx context.Context, userId int) (int, error) {
+	var sessionId int
+	err := a.DB.Write(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Users.Get(userId); !ok {
+			return domain.ErrUserNotFound
+		}
+
+		sessionId = tables.Sessions.NextId()
+		tables.Sessions.Put(sessionId, memory.Session{Id: sessionId, UserId: userId})
+
+		return nil
+	})
+
+	return sessionId, err
+}
+
+func (a *memoryAuthRepository) UnregisterSession(ctx context.Context, sessionId int) error {
+	return a.DB.Write(ctx, func(tables *memory.Tables) error {
+		tables.Sessions.Delete(sessionId)
+		return nil
+	})
+}
+
+func (a *memoryAuthRepository) UnregisterAllSession(ctx context.Context, userId int) error {
+	return a.DB.Write(ctx, func(tables *memory.Tables) error {
+		tables.Sessions.DeleteWhere(func(session memory.Session) bool {
+			return session.UserId == userId
+		})
+
+		return nil
+	})
+}
+
+func (a *memoryAuthRepository) GetPrincipal(ctx context.Context, sessionId int) (*domain.Principal, error) {
+	var principal domain.Principal
+	err := a.DB.Read(ctx, func(tables *memory.Tables) error {
+		session, ok := tables.Sessions.Get(sessionId)
+		if !ok {
+			return domain.ErrSessionNotFound
+		}
+
+		user, ok := tables.Users.Get(session.UserId)
+		if !ok {
+			return domain.ErrSessionNotFound
+		}
+
+		principal = domain.Principal{UserId: user.Id, Username: user.Username, Role: user.Role}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &principal, nil
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	sq "github.com/Masterminds/squirrel"
 	"petstore/internal/domain"
+	"petstore/internal/listing"
+	"petstore/internal/transaction"
 )
 
 type userRepository struct {
@@ -19,21 +21,26 @@ func NewUserRepository(conn *sql.DB) domain.UserRepository {
 
 func (u *userRepository) Create(ctx context.Context, user *domain.User) error {
 	query := u.SqlBuilder.Insert("users")
-	query = query.Columns("username", "first_name", "last_name", "email", "phone", "password", "user_status")
-	query = query.Values(user.Username, user.FirstName, user.LastName, user.Email, user.Phone, user.Password, user.UserStatus)
-	_, err := query.RunWith(u.Conn).ExecContext(ctx)
+	query = query.Columns("username", "first_name", "last_name", "email", "phone", "password", "user_status", "role")
+	query = query.Values(user.Username, user.FirstName, user.LastName, user.Email, user.Phone, user.Password,
+		user.UserStatus, user.Role)
+	_, err := query.RunWith(transaction.Conn(ctx, u.Conn)).ExecContext(ctx)
+	if transaction.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
+	}
 
 	return err
 }
 
 func (u *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
-	query := u.SqlBuilder.Select("id", "username", "first_name", "last_name", "email", "phone", "password", "user_status")
+	query := u.SqlBuilder.Select("id", "username", "first_name", "last_name", "email", "phone", "password", "user_status",
+		"role")
 	query = query.From("users").Where(sq.Eq{"username": username})
 
-	row := query.RunWith(u.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, u.Conn)).QueryRowContext(ctx)
 	user := &domain.User{}
 	err := row.Scan(&user.Id, &user.Username, &user.FirstName,
-		&user.LastName, &user.Email, &user.Phone, &user.Password, &user.UserStatus)
+		&user.LastName, &user.Email, &user.Phone, &user.Password, &user.UserStatus, &user.Role)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, domain.ErrUserNotFound
@@ -46,7 +53,7 @@ func (u *userRepository) GetIdByUsername(ctx context.Context, username string) (
 	query := u.SqlBuilder.Select("id")
 	query = query.From("users").Where(sq.Eq{"username": username})
 
-	row := query.RunWith(u.Conn).QueryRowContext(ctx)
+	row := query.RunWith(transaction.Conn(ctx, u.Conn)).QueryRowContext(ctx)
 	var userId int
 	err := row.Scan(&userId)
 
@@ -65,27 +72,71 @@ func (u *userRepository) Update(ctx context.Context, username string, user *doma
 		Set("email", user.Email).
 		Set("phone", user.Phone).
 		Set("password", user.Password).
-		Set("user_status", user.UserStatus)
+		Set("user_status", user.UserStatus).
+		Set("role", user.Role)
 
 	query = query.Where(sq.Eq{"username": username})
 
-	res, err := query.RunWith(u.Conn).ExecContext(ctx)
+	res, err := query.RunWith(transaction.Conn(ctx, u.Conn)).ExecContext(ctx)
+	if transaction.IsUniqueViolation(err) {
+		return domain.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
 	isUpdate, _ := res.RowsAffected()
 	if isUpdate == 0 {
 		return domain.ErrUserNotFound
 	}
 
-	return err
+	return nil
 }
 
 func (u *userRepository) Delete(ctx context.Context, username string) error {
 	query := u.SqlBuilder.Delete("users").Where(sq.Eq{"username": username})
 
-	res, err := query.RunWith(u.Conn).ExecContext(ctx)
+	res, err := query.RunWith(transaction.Conn(ctx, u.Conn)).ExecContext(ctx)
+	if transaction.IsForeignKeyViolation(err, "orders_user_id_fkey") {
+		return domain.ErrUserHasOrders
+	}
+	if err != nil {
+		return err
+	}
+
 	isDelete, _ := res.RowsAffected()
 	if isDelete == 0 {
 		return domain.ErrUserNotFound
 	}
 
-	return err
+	return nil
+}
+
+// roles are values of UserRole enum type
+var roles = listing.EnumValues(domain.Roles)
+
+// userColumns are fields of user available for sorting and filtering
+var userColumns = listing.Columns{
+	"id":         {Name: "id", Kind: listing.KindInt},
+	"username":   {Name: "username", Kind: listing.KindString},
+	"firstName":  {Name: "first_name", Kind: listing.KindString},
+	"lastName":   {Name: "last_name", Kind: listing.KindString},
+	"email":      {Name: "email", Kind: listing.KindString},
+	"phone":      {Name: "phone", Kind: listing.KindString},
+	"userStatus": {Name: "user_status", Kind: listing.KindInt},
+	"role":       {Name: "role", Kind: listing.KindEnum, Values: roles},
+}
+
+func (u *userRepository) List(ctx context.Context, params *domain.ListParams) ([]*domain.User, int, error) {
+	query := u.SqlBuilder.Select("id", "username", "first_name", "last_name", "email", "phone", "password", "user_status",
+		"role").From("users")
+
+	return listing.Query(ctx, transaction.Conn(ctx, u.Conn), query, params, userColumns, "id",
+		func(row sq.RowScanner) (*domain.User, error) {
+			var item domain.User
+			err := row.Scan(&item.Id, &item.Username, &item.FirstName,
+				&item.LastName, &item.Email, &item.Phone, &item.Password, &item.UserStatus, &item.Role)
+
+			return &item, err
+		})
 }
@@ -2,27 +2,39 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"petstore/internal/domain"
+	"petstore/internal/logging"
+	"petstore/internal/tracing"
 )
 
+// dummyPasswordHash is compared with password of unknown user, it has the same cost as stored hashes.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type userUsecase struct {
-	userRepo domain.UserRepository
-	authRepo domain.AuthRepository
-	jwtAuth  *jwtauth.JWTAuth
+	userRepo  domain.UserRepository
+	authRepo  domain.AuthRepository
+	jwtAuth   *jwtauth.JWTAuth
+	txManager domain.TxManager
 }
 
-func NewUserUsecase(ur domain.UserRepository, au domain.AuthRepository, jwt *jwtauth.JWTAuth) domain.UserUsecase {
-	return &userUsecase{userRepo: ur, authRepo: au, jwtAuth: jwt}
+func NewUserUsecase(ur domain.UserRepository, au domain.AuthRepository, jwt *jwtauth.JWTAuth,
+	tm domain.TxManager) domain.UserUsecase {
+	return &userUsecase{userRepo: ur, authRepo: au, jwtAuth: jwt, txManager: tm}
 }
 
-func (u *userUsecase) hashPassword(password string) string {
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (u *userUsecase) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
 
-	return string(hash)
+	return string(hash), nil
 }
 
 // checkPassword - compare hashed password with target password.
@@ -33,56 +45,153 @@ func (u *userUsecase) checkPassword(hashedPassword string, targetPassword string
 	return err == nil
 }
 
-func (u *userUsecase) Create(ctx context.Context, user *domain.User) error {
-	user.Password = u.hashPassword(user.Password)
+func (u *userUsecase) Create(ctx context.Context, input *domain.UserInput) (err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Create")
+	defer tracing.End(span, &err)
 
-	return u.userRepo.Create(ctx, user)
-}
+	user := domain.UserInputToUser(input)
+	user.Role = domain.RoleCustomer
 
-func (u *userUsecase) Get(ctx context.Context, username string) (*domain.User, error) {
-	return u.userRepo.GetByUsername(ctx, username)
+	return u.create(ctx, user)
 }
 
-func (u *userUsecase) Update(ctx context.Context, username string, user *domain.User) error {
-	user.Password = u.hashPassword(user.Password)
+func (u *userUsecase) create(ctx context.Context, user *domain.User) error {
+	password, err := u.hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = password
 
-	return u.userRepo.Update(ctx, username, user)
+	return u.userRepo.Create(ctx, user)
 }
 
-// Delete - delete user by username and delete all session of this user
-func (u *userUsecase) Delete(ctx context.Context, username string) error {
-	userId, err := u.userRepo.GetIdByUsername(ctx, username)
+func (u *userUsecase) Get(ctx context.Context, principal *domain.Principal,
+	username string) (output *domain.UserOutput, err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Get")
+	defer tracing.End(span, &err)
+
+	user, err := u.userRepo.GetByUsername(ctx, username)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = u.authRepo.UnregisterAllSession(ctx, userId)
-	if err != nil {
-		return err
+	return domain.UserToUserOutput(user, domain.UserVisibility(principal, user)), nil
+}
+
+func (u *userUsecase) Update(ctx context.Context, principal *domain.Principal, username string,
+	input *domain.UserInput) (err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Update")
+	defer tracing.End(span, &err)
+
+	if !principal.IsUser(username) && !principal.Can(domain.PermissionUserManage) {
+		return domain.ErrForbidden
 	}
 
-	return u.userRepo.Delete(ctx, username)
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userDb, err := u.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		user := domain.UserInputToUser(input)
+		if user.Role == "" || !principal.Can(domain.PermissionUserManage) {
+			user.Role = userDb.Role
+		}
+
+		user.Password, err = u.hashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+
+		return u.userRepo.Update(ctx, username, user)
+	})
 }
 
-func (u *userUsecase) CreateList(ctx context.Context, users []*domain.User) error {
-	for i, user := range users {
-		err := u.Create(ctx, user)
+// Delete - delete user by username and delete all session of this user
+func (u *userUsecase) Delete(ctx context.Context, principal *domain.Principal, username string) (err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Delete")
+	defer tracing.End(span, &err)
+
+	if !principal.IsUser(username) && !principal.Can(domain.PermissionUserManage) {
+		return domain.ErrForbidden
+	}
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userId, err := u.userRepo.GetIdByUsername(ctx, username)
 		if err != nil {
-			return fmt.Errorf("failed create user %d: %w", i, err)
+			return err
 		}
+
+		err = u.authRepo.UnregisterAllSession(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		return u.userRepo.Delete(ctx, username)
+	})
+}
+
+// CreateList - create all users or none of them.
+func (u *userUsecase) CreateList(ctx context.Context, inputs []*domain.UserInput) (err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.CreateList")
+	defer tracing.End(span, &err)
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, input := range inputs {
+			user := domain.UserInputToUser(input)
+			if user.Role == "" {
+				user.Role = domain.RoleCustomer
+			}
+
+			err := u.create(ctx, user)
+			if err != nil {
+				return fmt.Errorf("failed create user %d: %w", i, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *userUsecase) List(ctx context.Context,
+	params *domain.ListParams) (outputs []*domain.UserOutput, total int, err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.List")
+	defer tracing.End(span, &err)
+
+	users, total, err := u.userRepo.List(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	outputs = make([]*domain.UserOutput, 0, len(users))
+	for _, user := range users {
+		outputs = append(outputs, domain.UserToUserOutput(user, domain.VisibilityAdmin))
 	}
 
-	return nil
+	return outputs, total, nil
 }
 
-func (u *userUsecase) Login(ctx context.Context, username string, password string) (string, error) {
-	user, err := u.Get(ctx, username)
+func (u *userUsecase) Login(ctx context.Context, username string, password string) (token string, err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Login")
+	defer tracing.End(span, &err)
+
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// password is checked anyway, so response time does not show that user does not exist
+		u.checkPassword(string(dummyPasswordHash), password)
+
+		logging.FromContext(ctx).Info("login failed", zap.String("username", username), zap.Error(err))
+		return "", domain.InvalidCredentials(err)
+	}
 	if err != nil {
 		return "", err
 	}
 
 	if !u.checkPassword(user.Password, password) {
-		return "", fmt.Errorf("wrong password")
+		logging.FromContext(ctx).Info("login failed", zap.String("username", username),
+			zap.Error(domain.ErrWrongPassword))
+		return "", domain.InvalidCredentials(domain.ErrWrongPassword)
 	}
 
 	sessionId, err := u.authRepo.RegisterSession(ctx, user.Id)
@@ -90,29 +199,35 @@ func (u *userUsecase) Login(ctx context.Context, username string, password strin
 		return "", err
 	}
 
-	_, token, _ := u.jwtAuth.Encode(map[string]interface{}{"session_id": sessionId})
+	_, token, _ = u.jwtAuth.Encode(map[string]interface{}{"session_id": sessionId})
 	return token, nil
 }
 
-func (u *userUsecase) Logout(ctx context.Context, token string) error {
+func (u *userUsecase) Logout(ctx context.Context, token string) (err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Logout")
+	defer tracing.End(span, &err)
+
 	decodeToken, err := u.jwtAuth.Decode(token)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", domain.ErrInvalidToken, err)
 	}
 
 	sessionId, ok := decodeToken.Get("session_id")
 	if !ok {
-		return fmt.Errorf("session_id not found")
+		return fmt.Errorf("%w: session_id not found", domain.ErrInvalidToken)
 	}
 
 	return u.authRepo.UnregisterSession(ctx, int(sessionId.(float64)))
 }
 
-func (u *userUsecase) IsAuthenticated(ctx context.Context, token jwt.Token) (bool, error) {
+func (u *userUsecase) Authenticate(ctx context.Context, token jwt.Token) (principal *domain.Principal, err error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Authenticate")
+	defer tracing.End(span, &err)
+
 	sessionId, ok := token.Get("session_id")
 	if !ok {
-		return false, fmt.Errorf("session_id not found")
+		return nil, fmt.Errorf("%w: session_id not found", domain.ErrInvalidToken)
 	}
 
-	return u.authRepo.ExistsSession(ctx, int(sessionId.(float64)))
+	return u.authRepo.GetPrincipal(ctx, int(sessionId.(float64)))
 }
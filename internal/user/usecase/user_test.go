@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"petstore/internal/domain"
+	"petstore/internal/memory"
+	"petstore/internal/user/repository"
+	"strings"
+	"testing"
+)
+
+// newMemoryUsecase create usecase over memory repositories and returns their db.
+func newMemoryUsecase() (domain.UserUsecase, *memory.DB) {
+	db := memory.NewDB()
+
+	return NewUserUsecase(repository.NewMemoryUserRepository(db), repository.NewMemoryAuthRepository(db),
+		jwtauth.New("HS256", []byte("secret"), nil), memory.NewTxManager(db)), db
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newMemoryUsecase()
+
+	if err := usecase.Create(ctx, &domain.UserInput{Username: "alice", Password: "password"}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if token, err := usecase.Login(ctx, "alice", "password"); err != nil || token == "" {
+		t.Fatalf("login: got token %q, error %v", token, err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		reason   error
+	}{
+		{name: "unknown user", username: "bob", reason: domain.ErrUserNotFound},
+		{name: "wrong password", username: "alice", reason: domain.ErrWrongPassword},
+	}
+
+	for _, tt := range tests {
+		_, err := usecase.Login(ctx, tt.username, "wrong")
+
+		if !errors.Is(err, domain.ErrInvalidCredentials) || !errors.Is(err, tt.reason) {
+			t.Errorf("%s: got %v, want invalid credentials with reason %v", tt.name, err, tt.reason)
+		}
+
+		// clients get the same error for both reasons
+		if err.Error() != "invalid credentials" || domain.CodeOf(err) != "invalid_credentials" ||
+			domain.KindOf(err) != domain.KindUnauthorized {
+			t.Errorf("%s: got message %q, code %q, kind %d", tt.name, err.Error(), domain.CodeOf(err), domain.KindOf(err))
+		}
+	}
+}
+
+func TestDeleteUserWithOrders(t *testing.T) {
+	ctx := context.Background()
+	usecase, db := newMemoryUsecase()
+
+	if err := usecase.Create(ctx, &domain.UserInput{Username: "alice", Password: "password"}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if _, err := usecase.Login(ctx, "alice", "password"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	alice := &domain.Principal{UserId: 1, Username: "alice", Role: domain.RoleCustomer}
+	err := db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Categories.Put(1, domain.Category{Id: 1, Name: "dogs"})
+		tables.Pets.Put(1, domain.PetDTO{Id: 1, CategoryId: 1, Name: "rex", Status: domain.PetStatusPending})
+		tables.Orders.Put(1, domain.Order{Id: 1, UserId: alice.UserId, PetId: 1, Status: domain.PlacedOrderStatus})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// orders reference user, as foreign key of postgres
+	if err := usecase.Delete(ctx, alice, "alice"); !errors.Is(err, domain.ErrUserHasOrders) {
+		t.Fatalf("got %v, want %v", err, domain.ErrUserHasOrders)
+	}
+
+	// sessions are removed before user, so they are restored by rollback
+	err = db.Read(ctx, func(tables *memory.Tables) error {
+		if _, ok := tables.Sessions.Find(func(session memory.Session) bool { return session.UserId == alice.UserId }); !ok {
+			t.Error("session is not restored after failed delete")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Write(ctx, func(tables *memory.Tables) error {
+		tables.Orders.Delete(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := usecase.Delete(ctx, alice, "alice"); err != nil {
+		t.Fatalf("delete user without orders: %v", err)
+	}
+}
+
+func TestCreateListWithTooLongPassword(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newMemoryUsecase()
+
+	// bcrypt does not hash passwords longer than 72 bytes, user with empty hash can not be created
+	admin := &domain.UserInput{Username: "admin", Password: strings.Repeat("a", 73), Role: domain.RoleAdmin}
+	if err := usecase.CreateList(ctx, []*domain.UserInput{admin}); err == nil {
+		t.Fatal("got nil error")
+	}
+
+	if _, err := usecase.Get(ctx, nil, "admin"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("got %v, want %v", err, domain.ErrUserNotFound)
+	}
+}
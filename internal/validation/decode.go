@@ -0,0 +1,373 @@
+package validation
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"petstore/internal/domain"
+	"reflect"
+	"strings"
+)
+
+// MaxBodySize is max size of body of request in bytes.
+const MaxBodySize = 1 << 20
+
+// Decode decode json or xml body of request to dst by Content-Type and validate it by Validate.
+// Body is json if Content-Type is not set. Body is limited by MaxBodySize, trailing data is not allowed.
+// Errors are validation errors of domain, fields with wrong types are returned as violations.
+func Decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	body := http.MaxBytesReader(w, r.Body, MaxBodySize)
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return DecodeJSON(body, dst)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: invalid content type %s", domain.ErrInvalidBody, contentType)
+	}
+
+	switch mediaType {
+	case "application/json":
+		return DecodeJSON(body, dst)
+	case "application/xml", "text/xml":
+		return DecodeXML(body, dst)
+	default:
+		return fmt.Errorf("%w: unsupported content type %s, expected application/json or application/xml",
+			domain.ErrInvalidBody, mediaType)
+	}
+}
+
+// DecodeJSON decode json to dst and validate it, unknown fields are not allowed.
+func DecodeJSON(body io.Reader, dst interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeJSONError(err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: body must contain single json value", domain.ErrInvalidBody)
+	}
+
+	return Validate(dst)
+}
+
+// DecodeXML decode xml to dst and validate it. Slices are decoded from children of root element,
+// e.g. <users><User>...</User><User>...</User></users>, name of root element is not checked.
+// Unknown elements are not allowed, as unknown fields of json.
+func DecodeXML(body io.Reader, dst interface{}) error {
+	value := reflect.ValueOf(dst)
+	list := value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Slice
+
+	root := newXMLNode(value.Type())
+	if list {
+		root = newXMLListNode(value.Elem().Type())
+	}
+
+	decoder := xml.NewTokenDecoder(&strictXMLReader{decoder: xml.NewDecoder(body), root: root})
+
+	var err error
+	if list {
+		err = decodeXMLList(decoder, value.Elem())
+	} else {
+		err = decoder.Decode(dst)
+	}
+
+	if err == nil {
+		err = checkXMLEnd(decoder)
+	}
+
+	if err != nil {
+		return decodeXMLError(err)
+	}
+
+	return Validate(dst)
+}
+
+// xmlNode is set of child elements, which are decoded to fields of type.
+type xmlNode struct {
+	children map[string]*xmlNode
+	// anyChildren - children are not checked, e.g. of types with own UnmarshalXML
+	anyChildren bool
+	// items is node of children with any name, which are decoded to elements of list
+	items *xmlNode
+}
+
+// anyXMLNode allows any children and their descendants.
+var anyXMLNode = &xmlNode{anyChildren: true}
+
+// child returns node of child element, ok is false if child is unknown.
+func (n *xmlNode) child(name string) (node *xmlNode, ok bool) {
+	switch {
+	case n.anyChildren:
+		return anyXMLNode, true
+	case n.items != nil:
+		return n.items, true
+	default:
+		node, ok = n.children[name]
+		return node, ok
+	}
+}
+
+var (
+	xmlUnmarshalerType  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlNameType         = reflect.TypeOf(xml.Name{})
+)
+
+// newXMLNode returns node of elements decoded to type t by encoding/xml.
+func newXMLNode(t reflect.Type) *xmlNode {
+	return buildXMLNode(t, make(map[reflect.Type]*xmlNode))
+}
+
+// newXMLListNode returns node of root element, whose children are decoded to elements of list t.
+func newXMLListNode(t reflect.Type) *xmlNode {
+	return &xmlNode{items: newXMLNode(t.Elem())}
+}
+
+// buildXMLNode - build node of type, nodes of built types are reused, so recursive types are supported.
+func buildXMLNode(t reflect.Type, built map[reflect.Type]*xmlNode) *xmlNode {
+	for t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	if implements(t, xmlUnmarshalerType) {
+		return anyXMLNode
+	}
+
+	if t.Kind() != reflect.Struct || implements(t, textUnmarshalerType) {
+		// value is text, it has no children
+		return &xmlNode{}
+	}
+
+	if node, ok := built[t]; ok {
+		return node
+	}
+
+	node := &xmlNode{children: map[string]*xmlNode{}}
+	built[t] = node
+	addXMLFields(node, t, built)
+
+	return node
+}
+
+// addXMLFields - add elements of struct fields to node, fields of embedded structs are added to the same node.
+func addXMLFields(node *xmlNode, t reflect.Type, built map[reflect.Type]*xmlNode) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if (!field.IsExported() && !field.Anonymous) || field.Type == xmlNameType {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(field.Tag.Get("xml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		switch flags {
+		case "", "omitempty":
+		case "any", "any,omitempty", "innerxml":
+			node.anyChildren = true
+			continue
+		default:
+			// attributes, text and comments are not elements
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct && !implements(embedded, xmlUnmarshalerType) {
+				addXMLFields(node, embedded, built)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		// path a>b is element a with children b
+		parent := node
+		path := strings.Split(name, ">")
+		for _, elem := range path[:len(path)-1] {
+			child, ok := parent.children[elem]
+			if !ok {
+				child = &xmlNode{children: map[string]*xmlNode{}}
+				parent.children[elem] = child
+			}
+			parent = child
+		}
+
+		parent.children[path[len(path)-1]] = buildXMLNode(field.Type, built)
+	}
+}
+
+// implements check that type or pointer to it implements interface.
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// strictXMLReader - read tokens of decoder and return error on elements, which are not decoded to fields of root.
+type strictXMLReader struct {
+	decoder *xml.Decoder
+	root    *xmlNode
+	// open is nodes of open elements
+	open []*xmlNode
+}
+
+func (r *strictXMLReader) Token() (xml.Token, error) {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case xml.StartElement:
+		node := r.root
+		if len(r.open) > 0 {
+			var ok bool
+			if node, ok = r.open[len(r.open)-1].child(token.Name.Local); !ok {
+				return nil, domain.FieldError(token.Name.Local,
+					fmt.Errorf("%w: unknown element %s", domain.ErrInvalidBody, token.Name.Local))
+			}
+		}
+
+		r.open = append(r.open, node)
+	case xml.EndElement:
+		r.open = r.open[:len(r.open)-1]
+	}
+
+	return token, nil
+}
+
+// decodeXMLList decode each child of root element to new element of list.
+func decodeXMLList(decoder *xml.Decoder, list reflect.Value) error {
+	if _, err := nextStartElement(decoder); err != nil {
+		return err
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			elem := reflect.New(list.Type().Elem())
+			if err := decoder.DecodeElement(elem.Interface(), &token); err != nil {
+				return err
+			}
+
+			list.Set(reflect.Append(list, elem.Elem()))
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// nextStartElement skip xml declaration, comments and spaces before root element.
+func nextStartElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// checkXMLEnd check that there is nothing but spaces and comments after root element.
+func checkXMLEnd(decoder *xml.Decoder) error {
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return decodeXMLError(err)
+		}
+
+		switch token := token.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if len(strings.TrimSpace(string(token))) != 0 {
+				return fmt.Errorf("%w: body must contain single xml element", domain.ErrInvalidBody)
+			}
+		default:
+			return fmt.Errorf("%w: body must contain single xml element", domain.ErrInvalidBody)
+		}
+	}
+}
+
+// decodeJSONError convert error of json decoder to domain error.
+func decodeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: body is empty", domain.ErrInvalidBody)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: body is not complete json", domain.ErrInvalidBody)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: malformed json at position %d", domain.ErrInvalidBody, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+
+		return domain.FieldError(field, fmt.Errorf("%w: %s must be %s", domain.ErrInvalidBody, field, typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// decoder has no type of this error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+
+		return domain.FieldError(field, fmt.Errorf("%w: unknown field %s", domain.ErrInvalidBody, field))
+	default:
+		return decodeError(err)
+	}
+}
+
+// decodeXMLError convert error of xml decoder to domain error.
+func decodeXMLError(err error) error {
+	var syntaxErr *xml.SyntaxError
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidBody):
+		// unknown element is already domain error
+		return err
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: body is empty", domain.ErrInvalidBody)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: malformed xml at line %d", domain.ErrInvalidBody, syntaxErr.Line)
+	default:
+		return decodeError(err)
+	}
+}
+
+// decodeError convert errors, which are common for json and xml.
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: body must not be larger than %d bytes", domain.ErrInvalidBody, maxBytesErr.Limit)
+	}
+
+	return fmt.Errorf("%w: %v", domain.ErrInvalidBody, err)
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"petstore/internal/domain"
+	"strings"
+	"testing"
+)
+
+const petXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Pet>
+	<id>1</id>
+	<Category><id>1</id><name>dogs</name></Category>
+	<name>doggie</name>
+	<tags><Tag><id>1</id><name>friendly</name></Tag></tags>
+	<status>available</status>
+	<photoUrls><photoUrl>/static/pets/1/photos/1.jpg</photoUrl></photoUrls>
+	<photos>
+		<photo>
+			<url>/static/pets/1/photos/1.jpg</url>
+			<thumbnails><thumbnail name="small">/static/pets/1/photos/1_small.jpg</thumbnail></thumbnails>
+		</photo>
+	</photos>
+</Pet>`
+
+func TestDecodeXML(t *testing.T) {
+	var pet domain.Pet
+	if err := DecodeXML(strings.NewReader(petXML), &pet); err != nil {
+		t.Fatal(err)
+	}
+
+	if pet.Name != "doggie" || pet.Category.Name != "dogs" || len(pet.Tags) != 1 || len(pet.Photos) != 1 {
+		t.Errorf("got %+v", pet)
+	}
+}
+
+func TestDecodeXMLUnknownElement(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{
+			name:  "root",
+			body:  strings.Replace(petXML, "<name>doggie</name>", "<name>doggie</name><owner>bob</owner>", 1),
+			field: "owner",
+		},
+		{
+			name:  "nested struct",
+			body:  strings.Replace(petXML, "<name>dogs</name>", "<name>dogs</name><parent>1</parent>", 1),
+			field: "parent",
+		},
+		{
+			name:  "wrapper of list",
+			body:  strings.Replace(petXML, "<Tag><id>1</id>", "<Label><id>1</id>", 1),
+			field: "Label",
+		},
+		{
+			name:  "child of text",
+			body:  strings.Replace(petXML, "<status>available</status>", "<status><code>available</code></status>", 1),
+			field: "code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pet domain.Pet
+			err := DecodeXML(strings.NewReader(tt.body), &pet)
+			if !errors.Is(err, domain.ErrInvalidBody) {
+				t.Fatalf("got %v, want invalid body", err)
+			}
+
+			violations := domain.ViolationsOf(err)
+			if len(violations) != 1 || violations[0].Field != tt.field {
+				t.Errorf("got violations %+v, want field %s", violations, tt.field)
+			}
+		})
+	}
+}
+
+func TestDecodeXMLListUnknownElement(t *testing.T) {
+	user := `<User><username>bob</username><password>secret</password><role>customer</role>%s</User>`
+
+	var users []*domain.UserInput
+	err := DecodeXML(strings.NewReader("<users>"+strings.Replace(user, "%s", "", 1)+"</users>"), &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 1 || users[0].Username != "bob" {
+		t.Fatalf("got %+v", users)
+	}
+
+	users = nil
+	err = DecodeXML(strings.NewReader("<users>"+strings.Replace(user, "%s", "<admin>true</admin>", 1)+"</users>"),
+		&users)
+	if !errors.Is(err, domain.ErrInvalidBody) {
+		t.Errorf("got %v, want invalid body", err)
+	}
+}
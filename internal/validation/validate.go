@@ -0,0 +1,226 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"petstore/internal/domain"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate check value by `validate` tags of its fields and returns all violations at once.
+//
+// Rules are separated by comma:
+//   - required: string is not empty, number is not zero, pointer and slice are not nil
+//   - min=N, max=N: number of characters of string, number of elements of slice or value of number
+//   - maxbytes=N: number of bytes of string
+//   - oneof=a b c: string is one of values
+//   - email: string is email address
+//
+// Empty values are checked only by required. Structs, pointers to structs and slices of them
+// are validated recursively, nil elements of slices are not allowed.
+// Names of fields are paths by json tags, e.g. "category.name", "tags[0].name" or "[1].username".
+func Validate(value interface{}) error {
+	var violations []domain.Violation
+	validateValue(reflect.ValueOf(value), "", &violations)
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.Field+" "+violation.Message)
+	}
+
+	return &domain.Error{
+		Kind:       domain.KindValidation,
+		Code:       domain.CodeOf(domain.ErrValidation),
+		Message:    fmt.Sprintf("%s: %s", domain.ErrValidation, strings.Join(messages, "; ")),
+		Violations: violations,
+		Err:        domain.ErrValidation,
+	}
+}
+
+// validateValue validate fields of structs and elements of slices.
+func validateValue(value reflect.Value, path string, violations *[]domain.Violation) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			validateValue(value.Elem(), path, violations)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elem := value.Index(i)
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				*violations = append(*violations, domain.Violation{Field: elemPath, Message: "must not be null"})
+				continue
+			}
+
+			validateValue(elem, elemPath, violations)
+		}
+	case reflect.Struct:
+		validateStruct(value, path, violations)
+	}
+}
+
+func validateStruct(value reflect.Value, path string, violations *[]domain.Violation) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if message := checkRules(fieldValue, field.Tag.Get("validate")); message != "" {
+			*violations = append(*violations, domain.Violation{Field: name, Message: message})
+			continue
+		}
+
+		validateValue(fieldValue, name, violations)
+	}
+}
+
+// fieldName returns name of field in json.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// checkRules returns message of first broken rule or empty string if value is valid.
+func checkRules(value reflect.Value, tag string) string {
+	if tag == "" {
+		return ""
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if value.IsZero() {
+				return "is required"
+			}
+
+			continue
+		}
+
+		// other rules are not checked for empty values
+		if value.IsZero() {
+			return ""
+		}
+
+		var message string
+		switch name {
+		case "min":
+			message = checkMin(value, mustAtoi(rule, arg))
+		case "max":
+			message = checkMax(value, mustAtoi(rule, arg))
+		case "maxbytes":
+			message = checkMaxBytes(value, mustAtoi(rule, arg))
+		case "oneof":
+			message = checkOneOf(value, strings.Fields(arg))
+		case "email":
+			message = checkEmail(value)
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q", rule))
+		}
+
+		if message != "" {
+			return message
+		}
+	}
+
+	return ""
+}
+
+func mustAtoi(rule string, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("validation: rule %q must have integer argument", rule))
+	}
+
+	return n
+}
+
+// size returns number of characters of string, number of elements of slice or value of integer.
+func size(value reflect.Value) (int64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(utf8.RuneCountInString(value.String())), "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return int64(value.Len()), "elements"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), ""
+	default:
+		panic(fmt.Sprintf("validation: size of %s is not supported", value.Kind()))
+	}
+}
+
+func checkMin(value reflect.Value, min int) string {
+	n, unit := size(value)
+	if n >= int64(min) {
+		return ""
+	}
+
+	if unit == "" {
+		return fmt.Sprintf("must be at least %d", min)
+	}
+
+	return fmt.Sprintf("must have at least %d %s", min, unit)
+}
+
+func checkMax(value reflect.Value, max int) string {
+	n, unit := size(value)
+	if n <= int64(max) {
+		return ""
+	}
+
+	if unit == "" {
+		return fmt.Sprintf("must be at most %d", max)
+	}
+
+	return fmt.Sprintf("must have at most %d %s", max, unit)
+}
+
+func checkMaxBytes(value reflect.Value, max int) string {
+	if len(value.String()) <= max {
+		return ""
+	}
+
+	return fmt.Sprintf("must have at most %d bytes", max)
+}
+
+func checkOneOf(value reflect.Value, values []string) string {
+	for _, allowed := range values {
+		if value.String() == allowed {
+			return ""
+		}
+	}
+
+	return "must be one of " + strings.Join(values, ", ")
+}
+
+func checkEmail(value reflect.Value) string {
+	address, err := mail.ParseAddress(value.String())
+	if err != nil || address.Address != value.String() {
+		return "must be email address"
+	}
+
+	return ""
+}